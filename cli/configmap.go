@@ -104,8 +104,9 @@ func HandleSubcommands(ctx ConfigMapCtx, subcommandMap ConfigMapType, usageMsg s
 
 		// Skip flags and their values
 		if strings.HasPrefix(arg, "-") {
-			// If flag has =, it's self-contained, otherwise skip next arg as value
-			if !strings.Contains(arg, "=") && i+1 < len(ctx.Args) && !strings.HasPrefix(ctx.Args[i+1], "-") {
+			// If flag has =, it's self-contained, otherwise skip next arg as
+			// value unless it's a bool flag like --dry-run
+			if !strings.Contains(arg, "=") && !isBoolGlobalFlag(arg) && i+1 < len(ctx.Args) && !strings.HasPrefix(ctx.Args[i+1], "-") {
 				i++ // skip the value
 			}
 			continue
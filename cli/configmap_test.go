@@ -0,0 +1,49 @@
+package cli
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHandleSubcommands(t *testing.T) {
+	defer func(flags []Flag) { GlobalFlags = flags }(GlobalFlags)
+	GlobalFlags = []Flag{
+		{Name: "workspace", Short: "W", Type: "string"},
+		{Name: "dry-run", Type: "bool"},
+	}
+
+	tests := []struct {
+		name     string
+		args     []string
+		wantCmd  string
+		wantArgs []string
+	}{
+		{name: "Plain", args: []string{"worktree", "add", "api", "x"}, wantCmd: "add", wantArgs: []string{"add", "api", "x"}},
+		{name: "Valued_flag", args: []string{"-W", "work", "worktree", "add", "api"}, wantCmd: "add", wantArgs: []string{"add", "api"}},
+		{name: "Dry_run_takes_no_value", args: []string{"--dry-run", "worktree", "add", "api", "x"}, wantCmd: "add", wantArgs: []string{"add", "api", "x"}},
+		{name: "Short_dry_run", args: []string{"-dry-run", "config", "show-preset", "p"}, wantCmd: "show-preset", wantArgs: []string{"show-preset", "p"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var gotCmd string
+			var gotArgs []string
+			runner := func(name string) Runner {
+				return func(ctx ConfigMapCtx) {
+					gotCmd, gotArgs = name, ctx.Args
+				}
+			}
+			subcommands := ConfigMapType{
+				"add":         {Runner: runner("add")},
+				"show-preset": {Runner: runner("show-preset")},
+				"api":         {Runner: runner("api")},
+				"worktree":    {Runner: runner("worktree")},
+			}
+
+			HandleSubcommands(ConfigMapCtx{Args: tt.args}, subcommands, "")
+			assert.Equal(t, tt.wantCmd, gotCmd)
+			assert.Equal(t, tt.wantArgs, gotArgs)
+		})
+	}
+}
@@ -31,6 +31,7 @@ func MiddlewareAssertWorkspace(r Runner) Runner {
 
 		workspaceFlag := fs.String("workspace", "", "Specify workspace in which to work")
 		wFlag := fs.String("W", "", "Shorthand for -workspace. This overwrites -workspace")
+		fs.Bool("dry-run", false, "Print sessions instead of creating them (read by cli.Run)")
 		fs.Parse(ctx.Args)
 
 		workspace := ""
@@ -44,15 +45,13 @@ func MiddlewareAssertWorkspace(r Runner) Runner {
 		}
 
 		if workspace == "current" {
-			name, ok := workspacer.CurrentSessionName()
+			name, meta, ok := workspacer.CurrentSessionMeta()
 			if !ok {
 				log.Error("no multiplexer session attached")
 				return
 			}
 
-			if strings.Contains(name, "-") {
-				workspace = strings.Split(name, "-")[0]
-			}
+			workspace = currentWorkspace(ctx.Config, name, meta)
 		}
 
 		wsConfig, ok := ctx.Config.Workspaces[workspace]
@@ -61,6 +60,10 @@ func MiddlewareAssertWorkspace(r Runner) Runner {
 			os.Exit(1)
 		}
 
+		if wsConfig.Multiplexer == "" {
+			wsConfig.Multiplexer = ctx.Config.DefaultMultiplexer
+		}
+
 		ctx.WorkspaceConfig = wsConfig
 		ctx.Args = fs.Args()
 
@@ -70,6 +73,37 @@ func MiddlewareAssertWorkspace(r Runner) Runner {
 	}
 }
 
+// currentWorkspace maps the session we're running in to a workspace key: by
+// its workspace tag when it has one, else by the longest workspace prefix its
+// name starts with, else by the text before its first dash.
+func currentWorkspace(c config.GlobalUserConfig, session string, meta workspacer.SessionMeta) string {
+	if meta.Workspace != "" {
+		if _, ok := c.Workspaces[meta.Workspace]; ok {
+			return meta.Workspace
+		}
+		for key, wc := range c.Workspaces {
+			if wc.Name == meta.Workspace {
+				return key
+			}
+		}
+	}
+
+	workspace, longest := "", 0
+	for key, wc := range c.Workspaces {
+		if wc.Prefix != "" && strings.HasPrefix(session, wc.Prefix+"-") && len(wc.Prefix) > longest {
+			workspace, longest = key, len(wc.Prefix)
+		}
+	}
+	if workspace != "" {
+		return workspace
+	}
+
+	if strings.Contains(session, "-") {
+		return strings.Split(session, "-")[0]
+	}
+	return ""
+}
+
 // MiddlewareConfigInjector - gets config and injects it in the ctx
 func MiddlewareConfigInjector(r Runner) Runner {
 	return func(ctx ConfigMapCtx) {
@@ -90,6 +124,41 @@ func MiddlewareConfigInjector(r Runner) Runner {
 		configPath, _ := config.GetDefaultConfigPath()
 		state.LoadedConfigPath = configPath
 
+		// Problems are reported but don't stop the command: most only affect
+		// one workspace or preset, and bootstrap is how missing paths get made.
+		// They go to stderr so they don't end up in output that's piped on, and
+		// not at all with --json.
+		if !machineReadable(ctx.Args) && log.LogLevel >= log.LogLevelQuiet {
+			printConfigProblems(configPath, ctx.Config.Validate())
+		}
+
 		r(ctx)
 	}
 }
+
+// maxConfigProblems is how many config problems are printed on startup; the
+// rest are left to `config check`.
+const maxConfigProblems = 5
+
+// printConfigProblems prints config problems to stderr the way `config check`
+// does, up to maxConfigProblems of them.
+func printConfigProblems(path string, errs []config.ValidationError) {
+	for i, e := range errs {
+		if i == maxConfigProblems {
+			fmt.Fprintf(os.Stderr, "...and %d more, run 'workspacer config check' for all of them\n", len(errs)-i)
+			break
+		}
+		fmt.Fprintf(os.Stderr, "%s:%d: %s: %s\n", path, e.Line, e.Path, e.Message)
+	}
+}
+
+// machineReadable reports whether the command was asked for output another
+// program will parse.
+func machineReadable(args []string) bool {
+	for _, a := range args {
+		if a == "--json" || a == "-json" || strings.HasPrefix(a, "--json=") || strings.HasPrefix(a, "-json=") {
+			return true
+		}
+	}
+	return false
+}
@@ -8,6 +8,8 @@ import (
 	"sort"
 	"strings"
 	"text/tabwriter"
+
+	"github.com/JamesTiberiusKirk/workspacer/state"
 )
 
 func Run(cm ConfigMapType) {
@@ -35,7 +37,10 @@ func Run(cm ConfigMapType) {
 	for i := 0; i < len(args); i++ {
 		arg := args[i]
 		if strings.HasPrefix(arg, "-") {
-			if !strings.Contains(arg, "=") && i+1 < len(args) && !strings.HasPrefix(args[i+1], "-") {
+			if flagName(arg) == "dry-run" {
+				state.DryRun = true
+			}
+			if !strings.Contains(arg, "=") && !isBoolGlobalFlag(arg) && i+1 < len(args) && !strings.HasPrefix(args[i+1], "-") {
 				i++ // skip the value
 			}
 			continue
@@ -78,6 +83,25 @@ func Run(cm ConfigMapType) {
 	command.Runner(customCtx)
 }
 
+// flagName strips the dashes and any =value from a flag argument.
+func flagName(arg string) string {
+	name := strings.TrimLeft(arg, "-")
+	name, _, _ = strings.Cut(name, "=")
+	return name
+}
+
+// isBoolGlobalFlag reports whether arg is a bool GlobalFlag, which never takes
+// the next argument as its value.
+func isBoolGlobalFlag(arg string) bool {
+	name := flagName(arg)
+	for _, f := range GlobalFlags {
+		if f.Type == "bool" && (f.Name == name || f.Short == name) {
+			return true
+		}
+	}
+	return false
+}
+
 func printHelp(cm ConfigMapType) {
 	w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
 	fmt.Fprintln(w, "Usage:\t")
@@ -98,6 +122,7 @@ func printHelp(cm ConfigMapType) {
 	fmt.Fprintln(w, "\tGlobal flags:")
 	fmt.Fprintln(w, "\t\t-h,help\tPrint this message.")
 	fmt.Fprintln(w, "\t\t-W,workspace\tDefine workspace.")
+	fmt.Fprintln(w, "\t\t-dry-run\tPrint the sessions that would be created instead of creating them.")
 	fmt.Fprintln(w)
 	fmt.Fprintln(w)
 
@@ -59,26 +59,35 @@ var ConfigMap cli.ConfigMapType = cli.ConfigMapType{
 		}),
 	},
 	"config": &cli.Command{
-		Description: "Config management commands. Usage: config [new|files]",
+		Description: "Config management commands. Usage: config [new|list|check|show-preset]",
 		Subcommands: commands.ConfigSubcommands, // For completion
 		Runner:      commands.RunConfigCommand,
 	},
 
 	"c,clone": &cli.Command{
-		Description: "Clone a project from github org or user",
-		Runner: func(ctx cli.ConfigMapCtx) {
-			// TODO: get list of all repos in an org and allow the user to clone one
-			// check if the directory already exists and mark it as so in the list
-			log.Info("CLONE, to be implemented")
-		},
+		Description: "Clone projects from the github org or user. Without names, browse every repo and pick several. Usage: clone [name...]",
+		Runner:      cli.MiddlewareCommon(commands.RunCloneCommand),
+	},
+
+	"bootstrap": &cli.Command{
+		Description: "Clone every missing repo of the github org or user, filtered. Safe to re-run. Usage: bootstrap [-topic t,...] [-language l,...] [-match regex] [-archived] [-jobs n]",
+		Runner:      cli.MiddlewareCommon(commands.RunBootstrapCommand),
+	},
+
+	"sync": &cli.Command{
+		Description: "Fetch every git project in the workspace and fast-forward the clean ones on their default branch. Usage: sync [-dirty] [-all-branches] [-jobs n]",
+		Runner:      cli.MiddlewareCommon(commands.RunSyncCommand),
+	},
+
+	"worktree": &cli.Command{
+		Description: "Git worktree commands. Open worktrees from the picker as project@branch. Usage: worktree add <project> <branch>",
+		Subcommands: commands.WorktreeSubcommands, // For completion
+		Runner:      cli.MiddlewareCommon(commands.RunWorktreeCommand),
 	},
 
 	"l,list": &cli.Command{
-		Description: "List open sessions in a workspace",
-		Runner: func(ctx cli.ConfigMapCtx) {
-			// TODO: implement tmux session list only for the workspace
-			log.Info("LIST, to be implemented")
-		},
+		Description: "List open sessions in a workspace with windows, state, activity and git info. Usage: list [--json] [--all]",
+		Runner:      cli.MiddlewareConfigInjector(commands.RunListCommand),
 	},
 
 	"s,search": &cli.Command{
@@ -91,7 +100,7 @@ var ConfigMap cli.ConfigMapType = cli.ConfigMapType{
 				}
 			}
 
-			workspacer.SearchGithubInUserOrOrg(ctx.WorkspaceConfig.GithubOrg, searchArgs)
+			workspacer.SearchGithubInUserOrOrg(ctx.WorkspaceConfig, searchArgs)
 		}),
 	},
 
@@ -139,7 +148,7 @@ var ConfigMap cli.ConfigMapType = cli.ConfigMapType{
 	"CA,close-all": &cli.Command{
 		Description: "Close all sessions in workspace",
 		Runner: cli.MiddlewareCommon(func(ctx cli.ConfigMapCtx) {
-			workspacer.CloseAllSessionsInWorkspace(ctx.WorkspaceConfig)
+			workspacer.CloseAllSessionsInWorkspace(ctx.WorkspaceConfig, ctx.Config.SessionPresets)
 		}),
 	},
 
@@ -155,8 +164,10 @@ var ConfigMap cli.ConfigMapType = cli.ConfigMapType{
 				return
 			}
 
-			template := "#{m:%s-*,#{session_name}}"
-			fmt.Printf(template, ctx.WorkspaceConfig.Prefix)
+			// Tagged sessions match on their workspace tag, untagged ones on the
+			// session name prefix.
+			template := "#{?#{@workspacer_workspace},#{==:#{@workspacer_workspace},%s},#{m:%s-*,#{session_name}}}"
+			fmt.Printf(template, ctx.WorkspaceConfig.Name, ctx.WorkspaceConfig.Prefix)
 		}),
 	},
 
@@ -175,7 +186,7 @@ var ConfigMap cli.ConfigMapType = cli.ConfigMapType{
 				return
 			}
 
-			workspacer.StartOrSwitchToTmuxPreset(sessionPreset, preset.Path, preset)
+			workspacer.StartOrSwitchToTmuxPreset(sessionPreset, preset.Path, preset, ctx.Config.DefaultMultiplexer)
 		}),
 	},
 
@@ -193,10 +204,26 @@ var ConfigMap cli.ConfigMapType = cli.ConfigMapType{
 				return
 			}
 
-			workspacer.StartOrSwitchToTmpSession(path)
+			// tmp works without a config file; only the default multiplexer is read from it.
+			mux := config.MuxTmux
+			if loadedConfig, err := config.LoadFromDefaultConfigPath(); err == nil && loadedConfig != nil {
+				mux = loadedConfig.DefaultMultiplexer
+			}
+
+			workspacer.StartOrSwitchToTmpSession(path, mux)
 		},
 	},
 
+	"snapshot": &cli.Command{
+		Description: "Save the workspace's open sessions (windows, pane cwds, layouts, commands). Usage: snapshot [list]",
+		Runner:      cli.MiddlewareCommon(commands.RunSnapshotCommand),
+	},
+
+	"restore": &cli.Command{
+		Description: "Rebuild workspace sessions from a snapshot. Usage: restore [snapshot] (defaults to the newest)",
+		Runner:      cli.MiddlewareCommon(commands.RunRestoreCommand),
+	},
+
 	"n,new": &cli.Command{
 		Description: "New project in the workspace project folder.",
 		Runner:      cli.MiddlewareCommon(commands.RunNewCommand),
@@ -328,6 +355,7 @@ func init() {
 	cli.GlobalFlags = []cli.Flag{
 		{Name: "workspace", Short: "W", Description: "Specify workspace", Type: "string"},
 		{Name: "debug", Short: "D", Description: "Debug mode", Type: "bool"},
+		{Name: "dry-run", Description: "Print the sessions that would be created instead of creating them", Type: "bool"},
 		{Name: "help", Short: "h", Description: "Show help", Type: "bool"},
 	}
 
@@ -152,7 +152,7 @@ func main() {
 			}
 		}
 
-		workspacer.SearchGithubInUserOrOrg(workspaceConfig.GithubOrg, searchArgs)
+		workspacer.SearchGithubInUserOrOrg(workspaceConfig, searchArgs)
 	case "a", "actions":
 		mainBranch := util.GetGitMainBranch(workspaceConfig, args[1])
 
@@ -188,7 +188,7 @@ func main() {
 		)
 
 	case "CA", "close-all":
-		workspacer.CloseAllSessionsInWorkspace(workspaceConfig)
+		workspacer.CloseAllSessionsInWorkspace(workspaceConfig, loadedConfig.SessionPresets)
 
 	case "get-tmux-workspace-filter":
 		template := "#{m:%s-*,#{session_name}}"
@@ -201,7 +201,7 @@ func main() {
 		}
 
 		sessionPreset := args[1]
-		workspacer.StartOrSwitchToTmuxPreset("dots", "", loadedConfig.SessionPresets[sessionPreset])
+		workspacer.StartOrSwitchToTmuxPreset("dots", "", loadedConfig.SessionPresets[sessionPreset], loadedConfig.DefaultMultiplexer)
 
 	case "new":
 		if len(args) < 2 {
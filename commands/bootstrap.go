@@ -0,0 +1,71 @@
+package commands
+
+import (
+	"flag"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/JamesTiberiusKirk/workspacer/cli"
+	"github.com/JamesTiberiusKirk/workspacer/log"
+	"github.com/JamesTiberiusKirk/workspacer/state"
+	"github.com/JamesTiberiusKirk/workspacer/workspacer"
+)
+
+// RunBootstrapCommand clones every repo of the workspace's org/user that
+// matches the filters and isn't in the workspace yet. Re-running it only
+// clones what's still missing.
+func RunBootstrapCommand(ctx cli.ConfigMapCtx) {
+	fs := flag.NewFlagSet("bootstrap", flag.ExitOnError)
+	topics := fs.String("topic", "", "Only repos with one of these topics (comma separated)")
+	languages := fs.String("language", "", "Only repos whose primary language is one of these (comma separated)")
+	match := fs.String("match", "", "Only repos whose name matches this regex")
+	archived := fs.Bool("archived", ctx.WorkspaceConfig.ShowArchivedRepos, "Include archived repos")
+	jobs := fs.Int("jobs", 8, "How many repos to clone at once")
+	fs.Parse(ctx.Args[1:])
+
+	filter := workspacer.RepoFilter{
+		Topics:    splitList(*topics),
+		Languages: splitList(*languages),
+		Archived:  *archived,
+	}
+	if *match != "" {
+		re, err := regexp.Compile(*match)
+		if err != nil {
+			log.Error("Invalid -match regex: %s", err.Error())
+			return
+		}
+		filter.Name = re
+	}
+
+	log.Info("Fetching repositories for %s...", ctx.WorkspaceConfig.GithubOrg)
+	plan, err := workspacer.PlanBootstrap(ctx.WorkspaceConfig, filter)
+	if err != nil {
+		log.Error("%s", err.Error())
+		return
+	}
+
+	fmt.Printf("%d matching repositories, %d already cloned\n", len(plan.Missing)+len(plan.Present), len(plan.Present))
+	if len(plan.Missing) == 0 {
+		fmt.Println("Nothing to clone")
+		return
+	}
+
+	if state.DryRun {
+		printDryRunClones(ctx.WorkspaceConfig, plan.Missing)
+		return
+	}
+
+	cloneWithProgress(ctx.WorkspaceConfig, plan.Missing, *jobs)
+}
+
+// splitList splits a comma separated flag value, dropping empty entries.
+func splitList(s string) []string {
+	var list []string
+	for _, v := range strings.Split(s, ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			list = append(list, v)
+		}
+	}
+	return list
+}
@@ -0,0 +1,110 @@
+package commands
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+
+	"github.com/JamesTiberiusKirk/workspacer/cli"
+	"github.com/JamesTiberiusKirk/workspacer/config"
+	"github.com/JamesTiberiusKirk/workspacer/log"
+	"github.com/JamesTiberiusKirk/workspacer/state"
+	"github.com/JamesTiberiusKirk/workspacer/ui/multiselect"
+	"github.com/JamesTiberiusKirk/workspacer/util"
+	"github.com/JamesTiberiusKirk/workspacer/workspacer"
+)
+
+// cloneWorkers is how many repos `clone` fetches at once.
+const cloneWorkers = 4
+
+// RunCloneCommand clones repos from the workspace's GitHub org or user: the
+// ones named on the command line, or those picked from a browser of every
+// repo. When a single repo is cloned, a session is opened for it.
+func RunCloneCommand(ctx cli.ConfigMapCtx) {
+	wc := ctx.WorkspaceConfig
+	if wc.GithubOrg == "" {
+		log.Error("Workspace %s has no org_github set", wc.Name)
+		return
+	}
+
+	repos := ctx.Args[1:]
+	if len(repos) == 0 {
+		var err error
+		repos, err = browseRepos(wc)
+		if err != nil {
+			log.Error("%s", err.Error())
+			return
+		}
+		if len(repos) == 0 {
+			return
+		}
+	}
+
+	if state.DryRun {
+		printDryRunClones(wc, repos)
+		return
+	}
+
+	results := cloneWithProgress(wc, repos, cloneWorkers)
+
+	if len(results) == 1 && results[0].Err == nil {
+		workspacer.StartOrSwitchToSession(wc, ctx.Config.SessionPresets, results[0].Repo)
+	}
+}
+
+// browseRepos lets the user pick repos of the workspace's org/user, with the
+// ones already in the workspace shown but not selectable.
+func browseRepos(wc config.WorkspaceConfig) ([]string, error) {
+	log.Info("Fetching repositories for %s...", wc.GithubOrg)
+	names, err := workspacer.GetRepoNames(wc)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch repositories: %w", err)
+	}
+	sort.Strings(names)
+
+	items := make([]multiselect.Item, len(names))
+	for i, n := range names {
+		items[i] = multiselect.Item{Display: n, Value: n}
+		if util.DoesProjectExist(wc, n) {
+			items[i].Note = "(cloned)"
+			items[i].Disabled = true
+		}
+	}
+
+	return multiselect.Run("Clone from "+wc.GithubOrg, items)
+}
+
+// printDryRunClones prints what cloning repos would run, for --dry-run.
+func printDryRunClones(wc config.WorkspaceConfig, repos []string) {
+	for _, r := range repos {
+		fmt.Printf("[dry-run] git clone %s %s\n", workspacer.RepoCloneURL(wc, r), filepath.Join(util.GetWorkspacePath(wc), r))
+	}
+}
+
+// cloneWithProgress clones repos in parallel, printing a line as each one
+// finishes and the failures at the end.
+func cloneWithProgress(wc config.WorkspaceConfig, repos []string, workers int) []workspacer.CloneResult {
+	fmt.Printf("Cloning %d repositories into %s\n", len(repos), util.GetWorkspacePath(wc))
+
+	results := workspacer.CloneRepos(wc, repos, workers, func(done int, r workspacer.CloneResult) {
+		status := "ok"
+		if r.Err != nil {
+			status = "failed"
+		}
+		fmt.Printf("  [%d/%d] %s %s\n", done, len(repos), r.Repo, status)
+	})
+
+	failed := 0
+	for _, r := range results {
+		if r.Err != nil {
+			if failed == 0 {
+				fmt.Println("\nFailed:")
+			}
+			failed++
+			fmt.Printf("  %s: %s\n", r.Repo, r.Err.Error())
+		}
+	}
+	fmt.Printf("\nCloned %d of %d repositories\n", len(results)-failed, len(results))
+
+	return results
+}
@@ -2,11 +2,15 @@ package commands
 
 import (
 	"fmt"
+	"os"
+	"sort"
+	"strings"
 
 	"github.com/JamesTiberiusKirk/workspacer/cli"
 	"github.com/JamesTiberiusKirk/workspacer/config"
 	"github.com/JamesTiberiusKirk/workspacer/log"
 	"github.com/JamesTiberiusKirk/workspacer/state"
+	"gopkg.in/yaml.v3"
 )
 
 // ConfigSubcommands defines the subcommands for the config command
@@ -19,6 +23,14 @@ var ConfigSubcommands = cli.ConfigMapType{
 		Description: "Show actually loaded config and environment file paths",
 		Runner:      runConfigFiles,
 	},
+	"check": {
+		Description: "Validate the config file. Usage: config check [file]",
+		Runner:      runConfigCheck,
+	},
+	"show-preset": {
+		Description: "Print a session preset with its extends resolved. Usage: config show-preset <name>",
+		Runner:      runConfigShowPreset,
+	},
 }
 
 func RunConfigCommand(ctx cli.ConfigMapCtx) {
@@ -60,3 +72,72 @@ func runConfigFiles(ctx cli.ConfigMapCtx) {
 		fmt.Println(state.LoadedEnvPath)
 	}
 }
+
+// runConfigCheck validates the default config file, or the one given, and
+// prints each problem as file:line so editors can jump to it. Exits 1 when
+// there are any.
+func runConfigCheck(ctx cli.ConfigMapCtx) {
+	path := ""
+	if len(ctx.Args) > 1 {
+		path = ctx.Args[1]
+	} else {
+		var err error
+		if path, err = config.GetDefaultConfigPath(); err != nil {
+			log.Error("%s", err.Error())
+			os.Exit(1)
+		}
+	}
+
+	conf, err := config.LoadGlobalConfig(path)
+	if err != nil {
+		os.Exit(1)
+	}
+
+	errs := conf.Validate()
+	for _, e := range errs {
+		fmt.Printf("%s:%d: %s: %s\n", path, e.Line, e.Path, e.Message)
+	}
+	if len(errs) > 0 {
+		fmt.Printf("%d problem(s) found\n", len(errs))
+		os.Exit(1)
+	}
+	fmt.Printf("%s is valid\n", path)
+}
+
+// runConfigShowPreset prints a session preset as the loader resolved it, with
+// everything it extends merged in.
+func runConfigShowPreset(ctx cli.ConfigMapCtx) {
+	if len(ctx.Args) < 2 {
+		fmt.Println("Usage: config show-preset <name>")
+		return
+	}
+	name := ctx.Args[1]
+
+	path, err := config.GetDefaultConfigPath()
+	if err != nil {
+		log.Error("%s", err.Error())
+		os.Exit(1)
+	}
+	conf, err := config.LoadGlobalConfig(path)
+	if err != nil {
+		os.Exit(1)
+	}
+
+	preset, ok := conf.SessionPresets[name]
+	if !ok {
+		names := []string{}
+		for n := range conf.SessionPresets {
+			names = append(names, n)
+		}
+		sort.Strings(names)
+		log.Error("Session preset %s not found, have: %s", name, strings.Join(names, ", "))
+		os.Exit(1)
+	}
+
+	b, err := yaml.Marshal(preset)
+	if err != nil {
+		log.Error("Failed to marshal preset: %s", err.Error())
+		os.Exit(1)
+	}
+	fmt.Print(string(b))
+}
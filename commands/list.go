@@ -0,0 +1,151 @@
+package commands
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"sort"
+	"text/tabwriter"
+	"time"
+
+	"github.com/JamesTiberiusKirk/workspacer/cli"
+	"github.com/JamesTiberiusKirk/workspacer/log"
+	"github.com/JamesTiberiusKirk/workspacer/workspacer"
+)
+
+// RunListCommand prints the open sessions of the workspace, or of every
+// workspace with --all. It only needs the config: with --all there's no
+// workspace to assert, so MiddlewareAssertWorkspace is applied here when
+// needed rather than around the command.
+func RunListCommand(ctx cli.ConfigMapCtx) {
+	fs := flag.NewFlagSet("list", flag.ExitOnError)
+	// Global flags come before the command name; they're handled elsewhere.
+	fs.String("workspace", "", "")
+	fs.String("W", "", "")
+	fs.Bool("dry-run", false, "")
+	jsonFlag := fs.Bool("json", false, "Print sessions as JSON")
+	allFlag := fs.Bool("all", false, "List sessions of every workspace")
+
+	fs.Parse(ctx.Args)
+	if fs.NArg() > 0 {
+		fs.Parse(fs.Args()[1:])
+	}
+
+	if !*allFlag {
+		cli.MiddlewareAssertWorkspace(func(ctx cli.ConfigMapCtx) {
+			sessions, err := workspacer.ListWorkspaceSessions(ctx.WorkspaceConfig)
+			if err != nil {
+				listError(*jsonFlag, "Failed to list sessions: %s", err)
+				if !*jsonFlag {
+					return
+				}
+			}
+			printSessions(sessions, *jsonFlag, false)
+		})(ctx)
+		return
+	}
+
+	names := make([]string, 0, len(ctx.Config.Workspaces))
+	for name := range ctx.Config.Workspaces {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	sessions := []workspacer.SessionStatus{}
+	for _, name := range names {
+		wc := ctx.Config.Workspaces[name]
+		if wc.Multiplexer == "" {
+			wc.Multiplexer = ctx.Config.DefaultMultiplexer
+		}
+		ws, err := workspacer.ListWorkspaceSessions(wc)
+		if err != nil {
+			listError(*jsonFlag, "Failed to list sessions for workspace %s: %s", name, err)
+			continue
+		}
+		sessions = append(sessions, ws...)
+	}
+	printSessions(sessions, *jsonFlag, true)
+}
+
+// listError reports a failed listing, on stderr with --json so stdout stays
+// parseable.
+func listError(asJSON bool, format string, args ...any) {
+	if asJSON {
+		fmt.Fprintf(os.Stderr, format+"\n", args...)
+		return
+	}
+	log.Error(format, args...)
+}
+
+// printSessions prints sessions as a table, or as a JSON array (empty rather
+// than null when there are none).
+func printSessions(sessions []workspacer.SessionStatus, asJSON, withWorkspace bool) {
+	if sessions == nil {
+		sessions = []workspacer.SessionStatus{}
+	}
+	if asJSON {
+		data, err := json.MarshalIndent(sessions, "", "  ")
+		if err != nil {
+			log.Error("Failed to marshal sessions: %s", err.Error())
+			return
+		}
+		fmt.Println(string(data))
+		return
+	}
+
+	if len(sessions) == 0 {
+		log.Info("No open sessions")
+		return
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	if withWorkspace {
+		fmt.Fprint(w, "WORKSPACE\t")
+	}
+	fmt.Fprintln(w, "PROJECT\tWINDOWS\tSTATE\tACTIVITY\tGIT")
+	for _, s := range sessions {
+		if withWorkspace {
+			fmt.Fprintf(w, "%s\t", s.Workspace)
+		}
+
+		windows := "-"
+		if s.Windows > 0 {
+			windows = fmt.Sprintf("%d", s.Windows)
+		}
+		state := "-"
+		if s.Attached != nil && *s.Attached {
+			state = "attached"
+		} else if s.Attached != nil {
+			state = "detached"
+		}
+		git := s.Branch
+		if git != "" && s.Changes > 0 {
+			git += fmt.Sprintf(" (%d)", s.Changes)
+		}
+		if git == "" {
+			git = "-"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", s.Project, windows, state, activityAge(s.LastActivity), git)
+	}
+	w.Flush()
+}
+
+// activityAge renders how long ago t was, in the picker's cache-age style, or
+// "-" when it isn't known.
+func activityAge(t *time.Time) string {
+	if t == nil {
+		return "-"
+	}
+	ago := time.Since(*t)
+	switch {
+	case ago < time.Minute:
+		return "now"
+	case ago < time.Hour:
+		return fmt.Sprintf("%dm ago", int(ago.Minutes()))
+	case ago < 24*time.Hour:
+		return fmt.Sprintf("%dh ago", int(ago.Hours()))
+	default:
+		return fmt.Sprintf("%dd ago", int(ago.Hours()/24))
+	}
+}
@@ -0,0 +1,78 @@
+package commands
+
+import (
+	"fmt"
+
+	"github.com/JamesTiberiusKirk/workspacer/cli"
+	"github.com/JamesTiberiusKirk/workspacer/log"
+	"github.com/JamesTiberiusKirk/workspacer/state"
+	"github.com/JamesTiberiusKirk/workspacer/workspacer"
+)
+
+// RunSnapshotCommand saves the workspace's open sessions, or lists saved
+// snapshots with `snapshot list`.
+func RunSnapshotCommand(ctx cli.ConfigMapCtx) {
+	if len(ctx.Args) > 1 {
+		switch ctx.Args[1] {
+		case "list":
+			listSnapshots(ctx)
+		default:
+			log.Info("Unknown subcommand: %s", ctx.Args[1])
+			log.Info("Usage: snapshot [list]")
+		}
+		return
+	}
+
+	id, err := workspacer.TakeSnapshot(ctx.WorkspaceConfig)
+	if err != nil {
+		log.Error("Failed to take snapshot: %s", err.Error())
+		return
+	}
+	if !state.DryRun {
+		log.Info("Saved snapshot %s for workspace: %s", id, ctx.WorkspaceConfig.Name)
+	}
+}
+
+func listSnapshots(ctx cli.ConfigMapCtx) {
+	snaps, err := workspacer.ListSnapshots(ctx.WorkspaceConfig)
+	if err != nil {
+		log.Error("Failed to list snapshots: %s", err.Error())
+		return
+	}
+	if len(snaps) == 0 {
+		log.Info("No snapshots for workspace: %s", ctx.WorkspaceConfig.Name)
+		return
+	}
+
+	fmt.Printf("Snapshots for workspace: %s\n", ctx.WorkspaceConfig.Name)
+	for _, snap := range snaps {
+		fmt.Printf("  %s  %s  %d sessions\n", snap.ID(), snap.CreatedAt.Format("2006-01-02 15:04:05"), len(snap.Sessions))
+	}
+}
+
+// RunRestoreCommand rebuilds the sessions from a snapshot (the newest when no
+// id is given), leaving already open sessions alone.
+func RunRestoreCommand(ctx cli.ConfigMapCtx) {
+	id := ""
+	if len(ctx.Args) > 1 {
+		id = ctx.Args[1]
+	}
+
+	snap, err := workspacer.LoadSnapshot(ctx.WorkspaceConfig, id)
+	if err != nil {
+		log.Error("Failed to load snapshot: %s", err.Error())
+		return
+	}
+
+	restored, err := workspacer.RestoreSnapshot(ctx.WorkspaceConfig, snap)
+	for _, name := range restored {
+		log.Info("Restored session: %s", name)
+	}
+	if err != nil {
+		log.Error("%s", err.Error())
+		return
+	}
+	if len(restored) == 0 {
+		log.Info("All sessions from the snapshot are already open")
+	}
+}
@@ -0,0 +1,72 @@
+package commands
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/JamesTiberiusKirk/workspacer/cli"
+	"github.com/JamesTiberiusKirk/workspacer/log"
+	"github.com/JamesTiberiusKirk/workspacer/state"
+	"github.com/JamesTiberiusKirk/workspacer/workspacer"
+)
+
+// RunSyncCommand fetches every git project of the workspace, sister repos
+// included, and fast-forwards the clean ones on their default branch.
+func RunSyncCommand(ctx cli.ConfigMapCtx) {
+	fs := flag.NewFlagSet("sync", flag.ExitOnError)
+	dirty := fs.Bool("dirty", false, "Also pull projects with uncommitted changes")
+	allBranches := fs.Bool("all-branches", false, "Also pull projects not on their default branch")
+	jobs := fs.Int("jobs", 8, "How many projects to sync at once")
+	fs.Parse(ctx.Args[1:])
+
+	opts := workspacer.SyncOptions{Dirty: *dirty, AllBranches: *allBranches, DryRun: state.DryRun}
+	results, err := workspacer.SyncWorkspace(ctx.WorkspaceConfig, opts, *jobs, func(done, total int, r workspacer.SyncResult) {
+		fmt.Printf("[%d/%d] %s %s\n", done, total, r.Project, r.Status)
+	})
+	if err != nil {
+		log.Error("%s", err.Error())
+		return
+	}
+	if len(results) == 0 {
+		fmt.Println("No git projects in workspace")
+		return
+	}
+
+	printSyncSummary(results)
+}
+
+// printSyncSummary prints a table of the projects that need attention, those
+// updated first, then the totals per status.
+func printSyncSummary(results []workspacer.SyncResult) {
+	order := []workspacer.SyncStatus{
+		workspacer.SyncUpdated,
+		workspacer.SyncConflicted,
+		workspacer.SyncFailed,
+		workspacer.SyncSkipped,
+	}
+
+	fmt.Println()
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "PROJECT\tBRANCH\tSTATUS\tDETAIL")
+	counts := map[workspacer.SyncStatus]int{}
+	for _, r := range results {
+		counts[r.Status]++
+	}
+	for _, status := range order {
+		for _, r := range results {
+			if r.Status == status {
+				fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", r.Project, r.Branch, r.Status, r.Detail)
+			}
+		}
+	}
+	w.Flush()
+
+	totals := []string{}
+	for _, status := range append(order, workspacer.SyncUpToDate) {
+		totals = append(totals, fmt.Sprintf("%d %s", counts[status], status))
+	}
+	fmt.Println(strings.Join(totals, ", "))
+}
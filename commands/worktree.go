@@ -0,0 +1,42 @@
+package commands
+
+import (
+	"fmt"
+
+	"github.com/JamesTiberiusKirk/workspacer/cli"
+	"github.com/JamesTiberiusKirk/workspacer/log"
+	"github.com/JamesTiberiusKirk/workspacer/workspacer"
+)
+
+// WorktreeSubcommands defines the subcommands for the worktree command
+var WorktreeSubcommands = cli.ConfigMapType{
+	"add": {
+		Description: "Check out a branch of a project in a new worktree and open it. Usage: worktree add <project> <branch>",
+		Runner:      runWorktreeAdd,
+	},
+}
+
+func RunWorktreeCommand(ctx cli.ConfigMapCtx) {
+	cli.HandleSubcommands(ctx, WorktreeSubcommands, "Usage: worktree [subcommand]")
+}
+
+func runWorktreeAdd(ctx cli.ConfigMapCtx) {
+	if len(ctx.Args) < 3 {
+		fmt.Println("Usage: worktree add <project> <branch>")
+		return
+	}
+	project, branch := ctx.Args[1], ctx.Args[2]
+
+	wt, err := workspacer.AddWorktree(ctx.WorkspaceConfig, project, branch)
+	if err != nil {
+		log.Error("%s", err.Error())
+		return
+	}
+	log.Info("Worktree for %s at %s", wt.Name(), wt.Path)
+
+	workspacer.StartOrSwitchToSession(
+		ctx.WorkspaceConfig,
+		ctx.Config.SessionPresets,
+		project+"@"+wt.Name(),
+	)
+}
@@ -11,8 +11,8 @@ import (
 type Orientation string
 
 const (
-	OrientationHorizontal Orientation = "horizontal"
-	OrientationVertical               = "vertical"
+	OrientationHorizontal Orientation = "horizontal" // split to the right of the previous pane
+	OrientationVertical   Orientation = "vertical"   // split below the previous pane
 )
 
 func (o Orientation) IsValid() bool {
@@ -31,10 +31,11 @@ type SisterRepoConfig struct {
 }
 
 type ProjectConfig struct {
-	Name          string            `yaml:"name"`
-	SubPath       string            `yaml:"sub_path,omitempty"`
-	SessionPreset string            `yaml:"session_preset,omitempty"`
+	Name          string             `yaml:"name"`
+	SubPath       string             `yaml:"sub_path,omitempty"`
+	SessionPreset string             `yaml:"session_preset,omitempty"`
 	SisterRepos   []SisterRepoConfig `yaml:"sister_repos,omitempty"`
+	Env           map[string]string  `yaml:"env,omitempty"`
 }
 
 type GithubBackend string
@@ -44,38 +45,93 @@ const (
 	GithubBackendCLI GithubBackend = "cli"
 )
 
-// MuxBackend selects the terminal multiplexer workspacer drives. Chosen at
+// ForgeKind is the code host a workspace's repos live on (see
+// workspacer.GetForge).
+type ForgeKind string
+
+const (
+	ForgeGitHub ForgeKind = "github" // default
+	ForgeGitLab ForgeKind = "gitlab" // gitlab.com or self-managed, set forge_url
+	ForgeGitea  ForgeKind = "gitea"  // Gitea or Forgejo, set forge_url
+)
+
+// MuxBackend selects the terminal multiplexer workspacer drives. Set per
+// workspace (`multiplexer`), globally (`default_multiplexer`), or overridden at
 // runtime via the WORKSPACER_MUX env var (see workspacer.GetBackend).
 type MuxBackend string
 
 const (
-	MuxTmux  MuxBackend = "tmux"  // default
-	MuxGtmux MuxBackend = "gtmux" // github.com/FyrmForge/gtmux
+	MuxTmux   MuxBackend = "tmux"   // default
+	MuxGtmux  MuxBackend = "gtmux"  // github.com/FyrmForge/gtmux
+	MuxZellij MuxBackend = "zellij" // github.com/zellij-org/zellij
 )
 
+// CloneProtocol picks which of the forge's clone URLs repos are cloned from
+// when no clone_url template is set.
+type CloneProtocol string
+
+const (
+	CloneSSH   CloneProtocol = "ssh" // default
+	CloneHTTPS CloneProtocol = "https"
+)
+
+// CloneOptions are extra `git clone` flags for a workspace's repos.
+type CloneOptions struct {
+	Depth             int      `yaml:"depth,omitempty"`              // --depth, 0 for full history
+	Filter            string   `yaml:"filter,omitempty"`             // --filter, e.g. blob:none
+	RecurseSubmodules bool     `yaml:"recurse_submodules,omitempty"` // --recurse-submodules
+	Args              []string `yaml:"args,omitempty"`               // any other git clone flags
+}
+
+// HooksConfig holds shell commands run around session lifecycle events. Each
+// gets WORKSPACER_WORKSPACE, WORKSPACER_PROJECT, WORKSPACER_SESSION and
+// WORKSPACER_PATH in its environment and runs in the session's path.
+type HooksConfig struct {
+	PreCreate  []string `yaml:"pre_create,omitempty"`  // failure aborts the create
+	PostCreate []string `yaml:"post_create,omitempty"` // e.g. docker compose up -d
+	PreAttach  []string `yaml:"pre_attach,omitempty"`
+	PostKill   []string `yaml:"post_kill,omitempty"` // e.g. docker compose down
+}
+
 type WorkspaceConfig struct {
-	Name                string          `yaml:"name"`
-	Prefix              string          `yaml:"prefix"`
-	Path                string          `yaml:"path"`
-	GithubOrg           string          `yaml:"org_github,omitempty"`
-	IsOrg               bool            `yaml:"is_org,omitempty"`
-	Projects            []ProjectConfig `yaml:"projects,omitempty"`
-	SessionPreset       string          `yaml:"session_preset,omitempty"`
-	Session             *SessionConfig  `yaml:"session_config,omitempty"`
-	ActiveProjectsFirst bool            `yaml:"active_projects_first,omitempty"`
-	EnableGitInfo       bool            `yaml:"enable_git_info,omitempty"`
-	EnableRemoteRepos   bool            `yaml:"enable_remote_repos,omitempty"`
-	GithubBackend       GithubBackend   `yaml:"github_backend,omitempty"` // "api" or "cli", defaults to "api"
-	EnableCache         bool            `yaml:"enable_cache,omitempty"`
-	EnableUsageTracking bool            `yaml:"enable_usage_tracking,omitempty"`
-	RecentAccessWindow  int             `yaml:"recent_access_window,omitempty"` // Default: 50
-	ShowArchivedRepos   bool            `yaml:"show_archived_repos,omitempty"`
+	Key                 string            `yaml:"-"` // its key under workspaces, set on load
+	Name                string            `yaml:"name"`
+	Prefix              string            `yaml:"prefix"`
+	Path                string            `yaml:"path"`
+	GithubOrg           string            `yaml:"org_github,omitempty"`
+	IsOrg               bool              `yaml:"is_org,omitempty"`
+	Projects            []ProjectConfig   `yaml:"projects,omitempty"`
+	SessionPreset       string            `yaml:"session_preset,omitempty"`
+	Session             *SessionConfig    `yaml:"session_config,omitempty"`
+	ActiveProjectsFirst bool              `yaml:"active_projects_first,omitempty"`
+	EnableGitInfo       bool              `yaml:"enable_git_info,omitempty"`
+	EnableRemoteRepos   bool              `yaml:"enable_remote_repos,omitempty"`
+	GithubBackend       GithubBackend     `yaml:"github_backend,omitempty"` // "api" or "cli", defaults to "api"
+	GithubHost          string            `yaml:"github_host,omitempty"`    // GitHub Enterprise Server host, defaults to github.com
+	GithubAPIURL        string            `yaml:"github_api_url,omitempty"` // REST API base URL, defaults to https://<github_host>/api/v3/
+	EnableCache         bool              `yaml:"enable_cache,omitempty"`
+	EnableUsageTracking bool              `yaml:"enable_usage_tracking,omitempty"`
+	RecentAccessWindow  int               `yaml:"recent_access_window,omitempty"` // Default: 50
+	ShowArchivedRepos   bool              `yaml:"show_archived_repos,omitempty"`
+	Multiplexer         MuxBackend        `yaml:"multiplexer,omitempty"` // "tmux", "gtmux" or "zellij", defaults to default_multiplexer
+	Hooks               HooksConfig       `yaml:"hooks,omitempty"`
+	Env                 map[string]string `yaml:"env,omitempty"`             // session environment for every project
+	Forge               ForgeKind         `yaml:"forge,omitempty"`           // "github", "gitlab" or "gitea", defaults to github
+	ForgeURL            string            `yaml:"forge_url,omitempty"`       // base URL of a self-hosted forge, e.g. https://gitlab.example.com
+	ForgeTokenEnv       string            `yaml:"forge_token_env,omitempty"` // env var holding the forge API token
+	CloneURL            string            `yaml:"clone_url,omitempty"`       // clone URL template with {host}, {owner} and {repo}
+	CloneProtocol       CloneProtocol     `yaml:"clone_protocol,omitempty"`  // "ssh" or "https", defaults to ssh
+	CloneOptions        CloneOptions      `yaml:"clone_options,omitempty"`
 }
 
 type PanesConfig struct {
+	Name        string      `yaml:"name,omitempty"`  // only identifies the pane to presets that extend this one
+	Merge       MergeMode   `yaml:"merge,omitempty"` // how this pane applies to the extended preset's
 	Command     string      `yaml:"command,omitempty"`
 	Orientation Orientation `yaml:"orientation,omitempty"`
-	Size        int         `yaml:"size,omitempty"`
+	Size        int         `yaml:"size,omitempty"`   // width percent; shorthand for width: N%
+	Width       string      `yaml:"width,omitempty"`  // "60%" or absolute cells, e.g. "120"
+	Height      string      `yaml:"height,omitempty"` // "30%" or absolute cells, e.g. "15"
 	Path        string      `yaml:"path,omitempty"`
 }
 
@@ -84,11 +140,15 @@ type WindowConfig struct {
 	Name   string        `yaml:"name,omitempty"`
 	Layout string        `yaml:"layout,omitempty"`
 	Path   string        `yaml:"path,omitempty"`
+	Merge  MergeMode     `yaml:"merge,omitempty"` // how this window applies to the extended preset's
 }
 
 type SessionConfig struct {
-	Windows []WindowConfig `yaml:"screens,omitempty"`
-	Path    string         `yaml:"path,omitempty"`
+	Extends string            `yaml:"extends,omitempty"` // preset this one builds on, see MergeSession
+	Windows []WindowConfig    `yaml:"screens,omitempty"`
+	Path    string            `yaml:"path,omitempty"`
+	Hooks   HooksConfig       `yaml:"hooks,omitempty"`
+	Env     map[string]string `yaml:"env,omitempty"`
 }
 
 func (c *SessionConfig) ListPanes() []PanesConfig {
@@ -100,11 +160,14 @@ func (c *SessionConfig) ListPanes() []PanesConfig {
 }
 
 type GlobalUserConfig struct {
-	DefaultWorkspace string                     `yaml:"default_workspace,omitempty"`
-	Workspaces       map[string]WorkspaceConfig `yaml:"workspaces,omitempty"`
-	SessionPresets   map[string]SessionConfig   `yaml:"session_presets,omitempty"`
-	GitPath          string                     `yaml:"git_path,omitempty"`
-	GithubPath       string                     `yaml:"github_path,omitempty"`
+	DefaultWorkspace   string                     `yaml:"default_workspace,omitempty"`
+	DefaultMultiplexer MuxBackend                 `yaml:"default_multiplexer,omitempty"` // used by workspaces without `multiplexer` and by tmp/from-preset
+	Workspaces         map[string]WorkspaceConfig `yaml:"workspaces,omitempty"`
+	SessionPresets     map[string]SessionConfig   `yaml:"session_presets,omitempty"`
+	GitPath            string                     `yaml:"git_path,omitempty"`
+	GithubPath         string                     `yaml:"github_path,omitempty"`
+
+	source []byte // the YAML this was loaded from, for Validate's line numbers
 }
 
 func (c *GlobalUserConfig) GetDefaultWorkspaceConf() (WorkspaceConfig, error) {
@@ -116,13 +179,6 @@ func (c *GlobalUserConfig) GetDefaultWorkspaceConf() (WorkspaceConfig, error) {
 	return wc, nil
 }
 
-func (c *GlobalUserConfig) IsValid() bool {
-	// TODO:
-	//	- Need to validate the different paths
-	//	- Need to validate session preset names
-	return false
-}
-
 const (
 	defaultConfigPath = ".config/workspacer/"
 	defaultConfigFile = "workspaces.yaml"
@@ -183,12 +239,22 @@ func LoadGlobalConfig(path string) (*GlobalUserConfig, error) {
 		fmt.Printf("Error unmarshaling config: %s\n", err.Error())
 		return nil, err
 	}
+	conf.source = b
+	for key, wc := range conf.Workspaces {
+		wc.Key = key
+		conf.Workspaces[key] = wc
+	}
+	if err := conf.resolvePresets(); err != nil {
+		fmt.Printf("Error resolving session presets: %s\n", err.Error())
+		return nil, err
+	}
 
 	return &conf, nil
 }
 
 func LoadFromDefaultConfigPath() (*GlobalUserConfig, error) {
-	fmt.Println("Loading global config")
+	// stderr, so it doesn't end up in front of output like list --json.
+	fmt.Fprintln(os.Stderr, "Loading global config")
 
 	configPath, err := GetDefaultConfigPath()
 	if err != nil {
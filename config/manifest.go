@@ -0,0 +1,34 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ManifestFile is the name of the session manifest a project can commit to
+// its repo.
+const ManifestFile = ".workspacer.yaml"
+
+// ProjectManifest is a project's .workspacer.yaml: a session layout plus the
+// sister repos and env it needs, merged over the workspace's config when the
+// project's session is created.
+type ProjectManifest struct {
+	SessionConfig `yaml:",inline"`
+	SisterRepos   []SisterRepoConfig `yaml:"sister_repos,omitempty"`
+}
+
+// ParseProjectManifest decodes a manifest. Unknown fields are errors, so a
+// typo doesn't silently drop part of the layout.
+func ParseProjectManifest(b []byte) (ProjectManifest, error) {
+	var m ProjectManifest
+	dec := yaml.NewDecoder(bytes.NewReader(b))
+	dec.KnownFields(true)
+	if err := dec.Decode(&m); err != nil && !errors.Is(err, io.EOF) {
+		return ProjectManifest{}, fmt.Errorf("invalid %s: %w", ManifestFile, err)
+	}
+	return m, nil
+}
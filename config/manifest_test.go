@@ -0,0 +1,53 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseProjectManifest(t *testing.T) {
+	m, err := ParseProjectManifest([]byte(`
+screens:
+  - name: dev
+    panes:
+      - command: npm run dev
+hooks:
+  post_create: [docker compose up -d]
+sister_repos:
+  - name: api
+    label: api
+`))
+	require.NoError(t, err)
+	assert.Equal(t, "npm run dev", m.Windows[0].Panes[0].Command)
+	assert.Equal(t, []string{"docker compose up -d"}, m.Hooks.PostCreate)
+	assert.Equal(t, []SisterRepoConfig{{Name: "api", Label: "api"}}, m.SisterRepos)
+
+	_, err = ParseProjectManifest([]byte("windows: []\n"))
+	assert.ErrorContains(t, err, "field windows not found")
+
+	m, err = ParseProjectManifest(nil)
+	require.NoError(t, err)
+	assert.Empty(t, m.Windows)
+}
+
+func TestMergeSession(t *testing.T) {
+	base := SessionConfig{
+		Windows: []WindowConfig{{Name: "editor"}, {Name: "shell"}},
+		Env:     map[string]string{"A": "1", "B": "1"},
+		Hooks:   HooksConfig{PostCreate: []string{"base"}},
+	}
+	over := SessionConfig{
+		Windows: []WindowConfig{{Name: "shell", Layout: "tiled"}, {Name: "logs"}, {}},
+		Env:     map[string]string{"B": "2"},
+		Hooks:   HooksConfig{PostCreate: []string{"over"}},
+	}
+
+	merged := MergeSession(base, over)
+
+	assert.Equal(t, []WindowConfig{{Name: "editor"}, {Name: "shell", Layout: "tiled"}, {Name: "logs"}, {}}, merged.Windows)
+	assert.Equal(t, map[string]string{"A": "1", "B": "2"}, merged.Env)
+	assert.Equal(t, []string{"base", "over"}, merged.Hooks.PostCreate)
+	assert.Equal(t, []WindowConfig{{Name: "editor"}, {Name: "shell"}}, base.Windows, "base is left alone")
+}
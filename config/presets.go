@@ -0,0 +1,210 @@
+package config
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// MergeMode is how a window or pane of a session config that extends another
+// (or of a project manifest) applies to the base's window or pane of the same
+// name.
+type MergeMode string
+
+const (
+	MergeDefault MergeMode = ""        // merge into the one of the same name, else append
+	MergeAppend  MergeMode = "append"  // add it, even when the name is taken
+	MergeReplace MergeMode = "replace" // replace the one of the same name wholesale
+	MergeRemove  MergeMode = "remove"  // drop the one of the same name
+)
+
+func (m MergeMode) IsValid() bool {
+	switch m {
+	case MergeDefault, MergeAppend, MergeReplace, MergeRemove:
+		return true
+	}
+	return false
+}
+
+// MergeSession lays over on top of base. Each of over's windows applies to
+// base's window of the same name per its merge mode; by default layout and
+// path set in over win and panes are merged the same way, by pane name.
+// Windows and panes without a match in base are appended. Env vars and path
+// from over win, and over's hooks run after base's. The result has no
+// extends or merge modes left.
+func MergeSession(base, over SessionConfig) SessionConfig {
+	merged := SessionConfig{
+		Path:    base.Path,
+		Windows: mergeWindows(base.Windows, over.Windows),
+	}
+	if over.Path != "" {
+		merged.Path = over.Path
+	}
+
+	if len(base.Env) > 0 || len(over.Env) > 0 {
+		merged.Env = map[string]string{}
+		for _, env := range []map[string]string{base.Env, over.Env} {
+			for k, v := range env {
+				merged.Env[k] = v
+			}
+		}
+	}
+
+	merged.Hooks = HooksConfig{
+		PreCreate:  concat(base.Hooks.PreCreate, over.Hooks.PreCreate),
+		PostCreate: concat(base.Hooks.PostCreate, over.Hooks.PostCreate),
+		PreAttach:  concat(base.Hooks.PreAttach, over.Hooks.PreAttach),
+		PostKill:   concat(base.Hooks.PostKill, over.Hooks.PostKill),
+	}
+	return merged
+}
+
+// ExtendSession resolves s's extends against presets, which have to be
+// resolved already.
+func ExtendSession(s SessionConfig, presets map[string]SessionConfig) SessionConfig {
+	return MergeSession(presets[s.Extends], s)
+}
+
+// merged is an item of a window or pane list being merged; only items from
+// the base list can be matched by name.
+type merged[T any] struct {
+	item T
+	base bool
+}
+
+// mergeItems applies over to base by name and merge mode, merging matched
+// items with mergeOne.
+func mergeItems[T any](base, over []T, name func(T) string, mode func(T) MergeMode, mergeOne func(base, over T) T) []T {
+	items := make([]merged[T], 0, len(base)+len(over))
+	for _, b := range base {
+		items = append(items, merged[T]{item: b, base: true})
+	}
+
+	for _, o := range over {
+		i := slices.IndexFunc(items, func(m merged[T]) bool {
+			return m.base && name(o) != "" && name(m.item) == name(o)
+		})
+		switch {
+		case mode(o) == MergeRemove:
+			if i >= 0 {
+				items = slices.Delete(items, i, i+1)
+			}
+		case i < 0 || mode(o) == MergeAppend:
+			items = append(items, merged[T]{item: mergeOne(*new(T), o)})
+		case mode(o) == MergeReplace:
+			items[i].item = mergeOne(*new(T), o)
+		default:
+			items[i].item = mergeOne(items[i].item, o)
+		}
+	}
+
+	var out []T
+	for _, m := range items {
+		out = append(out, m.item)
+	}
+	return out
+}
+
+func mergeWindows(base, over []WindowConfig) []WindowConfig {
+	return mergeItems(base, over,
+		func(w WindowConfig) string { return w.Name },
+		func(w WindowConfig) MergeMode { return w.Merge },
+		func(b, o WindowConfig) WindowConfig {
+			b.Name = o.Name
+			if o.Layout != "" {
+				b.Layout = o.Layout
+			}
+			if o.Path != "" {
+				b.Path = o.Path
+			}
+			b.Panes = mergePanes(b.Panes, o.Panes)
+			b.Merge = MergeDefault
+			return b
+		},
+	)
+}
+
+func mergePanes(base, over []PanesConfig) []PanesConfig {
+	return mergeItems(base, over,
+		func(p PanesConfig) string { return p.Name },
+		func(p PanesConfig) MergeMode { return p.Merge },
+		func(b, o PanesConfig) PanesConfig {
+			b.Name = o.Name
+			if o.Command != "" {
+				b.Command = o.Command
+			}
+			if o.Orientation != "" {
+				b.Orientation = o.Orientation
+			}
+			if o.Size != 0 {
+				b.Size = o.Size
+			}
+			if o.Width != "" {
+				b.Width = o.Width
+			}
+			if o.Height != "" {
+				b.Height = o.Height
+			}
+			if o.Path != "" {
+				b.Path = o.Path
+			}
+			b.Merge = MergeDefault
+			return b
+		},
+	)
+}
+
+func concat(a, b []string) []string {
+	if len(a)+len(b) == 0 {
+		return nil
+	}
+	return append(append([]string{}, a...), b...)
+}
+
+// resolvePresets replaces every session preset, and every workspace's inline
+// session_config, with the result of merging it over the preset it extends.
+// An extends cycle is an error; extending a preset that doesn't exist is left
+// to Validate.
+func (c *GlobalUserConfig) resolvePresets() error {
+	resolved := map[string]SessionConfig{}
+	for _, name := range sortedKeys(c.SessionPresets) {
+		if _, err := c.resolvePreset(name, resolved, nil); err != nil {
+			return err
+		}
+	}
+	if c.SessionPresets != nil {
+		c.SessionPresets = resolved
+	}
+
+	for key, wc := range c.Workspaces {
+		if wc.Session != nil {
+			s := ExtendSession(*wc.Session, resolved)
+			wc.Session = &s
+			c.Workspaces[key] = wc
+		}
+	}
+	return nil
+}
+
+func (c *GlobalUserConfig) resolvePreset(name string, resolved map[string]SessionConfig, chain []string) (SessionConfig, error) {
+	if p, ok := resolved[name]; ok {
+		return p, nil
+	}
+	if slices.Contains(chain, name) {
+		return SessionConfig{}, fmt.Errorf("extends cycle: %s -> %s", strings.Join(chain, " -> "), name)
+	}
+	p, ok := c.SessionPresets[name]
+	if !ok {
+		return SessionConfig{}, nil
+	}
+
+	var base SessionConfig
+	if p.Extends != "" {
+		var err error
+		if base, err = c.resolvePreset(p.Extends, resolved, append(chain, name)); err != nil {
+			return SessionConfig{}, err
+		}
+	}
+	resolved[name] = MergeSession(base, p)
+	return resolved[name], nil
+}
@@ -0,0 +1,151 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func loadTestConfig(t *testing.T, yaml string) (*GlobalUserConfig, error) {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "workspaces.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(yaml), 0644))
+	return LoadGlobalConfig(path)
+}
+
+func TestResolvePresets(t *testing.T) {
+	conf, err := loadTestConfig(t, `
+workspaces:
+  work:
+    path: /tmp
+    session_config:
+      extends: go
+      screens:
+        - name: docs
+session_presets:
+  base:
+    env: {STAGE: dev, LOG: info}
+    hooks:
+      post_create: [base]
+    screens:
+      - name: editor
+        layout: main-vertical
+        panes:
+          - name: vim
+            command: nvim
+          - name: term
+      - name: shell
+        panes: [{}]
+      - name: logs
+        panes: [{command: tail -f log}]
+  go:
+    extends: base
+    env: {STAGE: test}
+    hooks:
+      post_create: [go]
+    screens:
+      - name: editor
+        panes:
+          - name: vim
+            width: 60%
+          - name: term
+            merge: remove
+          - command: go test ./...
+      - name: shell
+        merge: replace
+        panes: [{command: go run .}]
+      - name: logs
+        merge: remove
+      - name: server
+        panes: [{command: air}]
+  go-debug:
+    extends: go
+    screens:
+      - name: server
+        merge: append
+        panes: [{command: dlv debug}]
+`)
+	require.NoError(t, err)
+
+	base := conf.SessionPresets["base"]
+	assert.Len(t, base.Windows, 3, "presets without extends are kept as they are")
+	assert.Empty(t, base.Windows[0].Panes[1].Merge)
+
+	goPreset := conf.SessionPresets["go"]
+	assert.Empty(t, goPreset.Extends)
+	assert.Equal(t, []WindowConfig{
+		{Name: "editor", Layout: "main-vertical", Panes: []PanesConfig{
+			{Name: "vim", Command: "nvim", Width: "60%"},
+			{Command: "go test ./..."},
+		}},
+		{Name: "shell", Panes: []PanesConfig{{Command: "go run ."}}},
+		{Name: "server", Panes: []PanesConfig{{Command: "air"}}},
+	}, goPreset.Windows)
+	assert.Equal(t, map[string]string{"STAGE": "test", "LOG": "info"}, goPreset.Env)
+	assert.Equal(t, []string{"base", "go"}, goPreset.Hooks.PostCreate)
+
+	debug := conf.SessionPresets["go-debug"]
+	require.Len(t, debug.Windows, 4)
+	assert.Equal(t, WindowConfig{Name: "server", Panes: []PanesConfig{{Command: "dlv debug"}}}, debug.Windows[3])
+
+	session := conf.Workspaces["work"].Session
+	require.Len(t, session.Windows, 4, "inline session configs can extend presets too")
+	assert.Equal(t, "docs", session.Windows[3].Name)
+}
+
+func TestResolvePresetsCycle(t *testing.T) {
+	_, err := loadTestConfig(t, `
+session_presets:
+  a: {extends: b}
+  b: {extends: c}
+  c: {extends: a}
+  d: {extends: d}
+`)
+	assert.EqualError(t, err, "extends cycle: a -> b -> c -> a")
+}
+
+func TestValidatePresets(t *testing.T) {
+	conf, err := loadTestConfig(t, `
+session_presets:
+  base:
+    screens:
+      - name: editor
+        layout: nope
+  child:
+    extends: missing
+    screens:
+      - name: editor
+        merge: drop
+        panes:
+          - merge: keep
+`)
+	require.NoError(t, err)
+
+	var got []string
+	for _, e := range conf.Validate() {
+		got = append(got, e.Error())
+	}
+	assert.Equal(t, []string{
+		`line 6: session_presets.base.screens.0.layout: unknown layout "nope" (want one of even-horizontal, even-vertical, main-horizontal, main-horizontal-mirrored, main-vertical, main-vertical-mirrored, tiled)`,
+		`line 8: session_presets.child.extends: session preset "missing" isn't defined`,
+		`line 11: session_presets.child.screens.0.merge: unknown merge "drop" (want append, replace or remove)`,
+		`line 13: session_presets.child.screens.0.panes.0.merge: unknown merge "keep" (want append, replace or remove)`,
+	}, got)
+}
+
+func TestLoadGlobalConfigKeys(t *testing.T) {
+	conf, err := loadTestConfig(t, `
+workspaces:
+  work:
+    path: /tmp
+  home:
+    name: Home
+    path: /tmp
+`)
+	require.NoError(t, err)
+	assert.Equal(t, "work", conf.Workspaces["work"].Key)
+	assert.Equal(t, "home", conf.Workspaces["home"].Key)
+}
@@ -0,0 +1,329 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"text/template"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ValidationError is one problem in the config, at the line of the YAML it
+// was loaded from (0 when the config didn't come from a file).
+type ValidationError struct {
+	Line    int
+	Path    string // where in the config, e.g. workspaces.work.session_preset
+	Message string
+}
+
+func (e ValidationError) Error() string {
+	if e.Line > 0 {
+		return fmt.Sprintf("line %d: %s: %s", e.Line, e.Path, e.Message)
+	}
+	return fmt.Sprintf("%s: %s", e.Path, e.Message)
+}
+
+// tmuxLayouts are the preset layouts tmux's select-layout takes. A custom
+// layout string (as printed by `tmux list-windows`) is accepted too.
+var tmuxLayouts = []string{
+	"even-horizontal", "even-vertical",
+	"main-horizontal", "main-horizontal-mirrored",
+	"main-vertical", "main-vertical-mirrored",
+	"tiled",
+}
+
+var customLayout = regexp.MustCompile(`^[0-9a-f]{4},\d+x\d+,\d+,\d+`)
+
+// IsValidLayout reports whether l is a tmux layout name or custom layout
+// string.
+func IsValidLayout(l string) bool {
+	for _, name := range tmuxLayouts {
+		if l == name {
+			return true
+		}
+	}
+	return customLayout.MatchString(l)
+}
+
+func (m MuxBackend) IsValid() bool {
+	switch m {
+	case MuxTmux, MuxGtmux, MuxZellij:
+		return true
+	}
+	return false
+}
+
+func (b GithubBackend) IsValid() bool {
+	switch b {
+	case GithubBackendAPI, GithubBackendCLI:
+		return true
+	}
+	return false
+}
+
+func (f ForgeKind) IsValid() bool {
+	switch f {
+	case ForgeGitHub, ForgeGitLab, ForgeGitea:
+		return true
+	}
+	return false
+}
+
+func (p CloneProtocol) IsValid() bool {
+	switch p {
+	case CloneSSH, CloneHTTPS:
+		return true
+	}
+	return false
+}
+
+// validator collects problems, looking up their lines in the YAML source.
+type validator struct {
+	root *yaml.Node
+	errs []ValidationError
+}
+
+func (v *validator) add(path []string, format string, args ...any) {
+	v.errs = append(v.errs, ValidationError{
+		Line:    lineOf(v.root, path),
+		Path:    strings.Join(path, "."),
+		Message: fmt.Sprintf(format, args...),
+	})
+}
+
+// lineOf finds the line of the key or item at path in the YAML document root, or
+// of the deepest part of path that's there.
+func lineOf(root *yaml.Node, path []string) int {
+	if root == nil {
+		return 0
+	}
+	n := root
+	if n.Kind == yaml.DocumentNode && len(n.Content) > 0 {
+		n = n.Content[0]
+	}
+
+	line := n.Line
+	for _, key := range path {
+		var next *yaml.Node
+		switch n.Kind {
+		case yaml.MappingNode:
+			for i := 0; i+1 < len(n.Content); i += 2 {
+				if n.Content[i].Value == key {
+					// The key's line, so a block value points at its name.
+					next, line = n.Content[i+1], n.Content[i].Line
+					break
+				}
+			}
+		case yaml.SequenceNode:
+			if i, err := strconv.Atoi(key); err == nil && i < len(n.Content) {
+				next, line = n.Content[i], n.Content[i].Line
+			}
+		}
+		if next == nil {
+			break
+		}
+		n = next
+	}
+	return line
+}
+
+// Validate checks the config for mistakes that loading it doesn't catch:
+// workspace paths that don't exist, clashing prefixes, session presets and
+// sister repos that aren't there, sub_paths outside their project, and
+// unknown layouts, orientations, merge modes and backends, and templates
+// that don't parse. Problems are
+// sorted by line.
+func (c *GlobalUserConfig) Validate() []ValidationError {
+	v := &validator{}
+	if len(c.source) > 0 {
+		var root yaml.Node
+		if yaml.Unmarshal(c.source, &root) == nil {
+			v.root = &root
+		}
+	}
+
+	if c.DefaultWorkspace != "" {
+		if _, ok := c.Workspaces[c.DefaultWorkspace]; !ok {
+			v.add([]string{"default_workspace"}, "workspace %q isn't defined", c.DefaultWorkspace)
+		}
+	}
+	if c.DefaultMultiplexer != "" && !c.DefaultMultiplexer.IsValid() {
+		v.add([]string{"default_multiplexer"}, "unknown multiplexer %q (want tmux, gtmux or zellij)", c.DefaultMultiplexer)
+	}
+
+	// Session configs are checked as written, before extends are resolved,
+	// so a problem is reported once, at the preset that has it.
+	raw := c
+	if len(c.source) > 0 {
+		var r GlobalUserConfig
+		if yaml.Unmarshal(c.source, &r) == nil {
+			raw = &r
+		}
+	}
+
+	for _, name := range sortedKeys(c.Workspaces) {
+		c.validateWorkspace(v, name, raw.Workspaces[name].Session)
+	}
+	c.validatePrefixes(v)
+
+	for _, name := range sortedKeys(raw.SessionPresets) {
+		c.validateSession(v, []string{"session_presets", name}, raw.SessionPresets[name])
+	}
+
+	sort.SliceStable(v.errs, func(i, j int) bool { return v.errs[i].Line < v.errs[j].Line })
+	return v.errs
+}
+
+// IsValid reports whether Validate finds no problems.
+func (c *GlobalUserConfig) IsValid() bool {
+	return len(c.Validate()) == 0
+}
+
+func (c *GlobalUserConfig) validateWorkspace(v *validator, name string, session *SessionConfig) {
+	wc := c.Workspaces[name]
+	at := func(path ...string) []string {
+		return child([]string{"workspaces", name}, path...)
+	}
+
+	path := wc.Path
+	if strings.HasPrefix(path, "~/") {
+		home, _ := os.UserHomeDir()
+		path = filepath.Join(home, path[2:])
+	}
+	pathOK := false
+	if path == "" {
+		v.add(at(), "path is required")
+	} else if fi, err := os.Stat(path); err != nil {
+		v.add(at("path"), "%s doesn't exist", wc.Path)
+	} else if !fi.IsDir() {
+		v.add(at("path"), "%s isn't a directory", wc.Path)
+	} else {
+		pathOK = true
+	}
+
+	c.checkPreset(v, at("session_preset"), wc.SessionPreset)
+	if session != nil {
+		c.validateSession(v, at("session_config"), *session)
+	}
+
+	if wc.Multiplexer != "" && !wc.Multiplexer.IsValid() {
+		v.add(at("multiplexer"), "unknown multiplexer %q (want tmux, gtmux or zellij)", wc.Multiplexer)
+	}
+	if wc.GithubBackend != "" && !wc.GithubBackend.IsValid() {
+		v.add(at("github_backend"), "unknown github backend %q (want api or cli)", wc.GithubBackend)
+	}
+	if wc.Forge != "" && !wc.Forge.IsValid() {
+		v.add(at("forge"), "unknown forge %q (want github, gitlab or gitea)", wc.Forge)
+	}
+	if wc.CloneProtocol != "" && !wc.CloneProtocol.IsValid() {
+		v.add(at("clone_protocol"), "unknown clone protocol %q (want ssh or https)", wc.CloneProtocol)
+	}
+
+	for i, p := range wc.Projects {
+		pat := at("projects", strconv.Itoa(i))
+		c.checkPreset(v, child(pat, "session_preset"), p.SessionPreset)
+		if filepath.IsAbs(p.SubPath) || strings.HasPrefix(filepath.Clean(p.SubPath), "..") {
+			v.add(child(pat, "sub_path"), "%s must be a path inside the project", p.SubPath)
+		}
+		for j, sr := range p.SisterRepos {
+			sat := child(pat, "sister_repos", strconv.Itoa(j))
+			c.checkPreset(v, child(sat, "session_preset"), sr.SessionPreset)
+			if sr.Name == "" {
+				v.add(sat, "name is required")
+			} else if pathOK {
+				if _, err := os.Stat(filepath.Join(path, sr.Name)); err != nil {
+					v.add(child(sat, "name"), "%s isn't in the workspace folder", sr.Name)
+				}
+			}
+		}
+	}
+}
+
+func (c *GlobalUserConfig) checkPreset(v *validator, path []string, preset string) {
+	if preset == "" {
+		return
+	}
+	if _, ok := c.SessionPresets[preset]; !ok {
+		v.add(path, "session preset %q isn't defined", preset)
+	}
+}
+
+// validatePrefixes reports prefixes used by two workspaces, and prefixes
+// that start another one's, e.g. wk and wk-api: a session named
+// wk-api-web could then be either workspace's.
+func (c *GlobalUserConfig) validatePrefixes(v *validator) {
+	names := sortedKeys(c.Workspaces)
+	for i, a := range names {
+		pa := c.Workspaces[a].Prefix
+		if pa == "" {
+			continue
+		}
+		for _, b := range names[i+1:] {
+			pb := c.Workspaces[b].Prefix
+			switch {
+			case pb == "":
+			case pa == pb:
+				v.add([]string{"workspaces", b, "prefix"}, "prefix %q is also used by workspace %q", pb, a)
+			case strings.HasPrefix(pb, pa+"-"):
+				v.add([]string{"workspaces", b, "prefix"}, "prefix %q overlaps %q of workspace %q", pb, pa, a)
+			case strings.HasPrefix(pa, pb+"-"):
+				v.add([]string{"workspaces", a, "prefix"}, "prefix %q overlaps %q of workspace %q", pa, pb, b)
+			}
+		}
+	}
+}
+
+func (c *GlobalUserConfig) validateSession(v *validator, path []string, s SessionConfig) {
+	c.checkPreset(v, child(path, "extends"), s.Extends)
+	for i, w := range s.Windows {
+		wat := child(path, "screens", strconv.Itoa(i))
+		checkTemplate(v, child(wat, "name"), w.Name)
+		checkTemplate(v, child(wat, "path"), w.Path)
+		if !w.Merge.IsValid() {
+			v.add(child(wat, "merge"), "unknown merge %q (want append, replace or remove)", w.Merge)
+		}
+		if w.Layout != "" && !IsValidLayout(w.Layout) {
+			v.add(child(wat, "layout"), "unknown layout %q (want one of %s)", w.Layout, strings.Join(tmuxLayouts, ", "))
+		}
+		for j, p := range w.Panes {
+			checkTemplate(v, child(wat, "panes", strconv.Itoa(j), "command"), p.Command)
+			checkTemplate(v, child(wat, "panes", strconv.Itoa(j), "path"), p.Path)
+			if !p.Merge.IsValid() {
+				v.add(child(wat, "panes", strconv.Itoa(j), "merge"), "unknown merge %q (want append, replace or remove)", p.Merge)
+			}
+			if p.Orientation != "" && !p.Orientation.IsValid() {
+				v.add(child(wat, "panes", strconv.Itoa(j), "orientation"), "unknown orientation %q (want horizontal or vertical)", p.Orientation)
+			}
+		}
+	}
+}
+
+// checkTemplate reports s when it uses {{ }} that doesn't parse as a Go
+// template.
+func checkTemplate(v *validator, path []string, s string) {
+	if !strings.Contains(s, "{{") {
+		return
+	}
+	if _, err := template.New(path[len(path)-1]).Parse(s); err != nil {
+		v.add(path, "invalid template: %s", strings.TrimPrefix(err.Error(), "template: "))
+	}
+}
+
+// child is path extended by keys, never sharing path's backing array.
+func child(path []string, keys ...string) []string {
+	return append(append([]string{}, path...), keys...)
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
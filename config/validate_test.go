@@ -0,0 +1,153 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidate(t *testing.T) {
+	ws := t.TempDir()
+	require.NoError(t, os.Mkdir(filepath.Join(ws, "api-docs"), 0755))
+
+	tests := []struct {
+		name string
+		yaml string
+		want []string
+	}{
+		{
+			name: "Valid",
+			yaml: `default_workspace: work
+workspaces:
+  work:
+    prefix: wk
+    path: ` + ws + `
+    session_preset: dev
+    projects:
+      - name: api
+        sister_repos:
+          - name: api-docs
+            label: docs
+session_presets:
+  dev:
+    screens:
+      - layout: main-vertical
+        panes:
+          - orientation: horizontal
+`,
+		},
+		{
+			name: "Missing_references",
+			yaml: `default_workspace: home
+workspaces:
+  work:
+    prefix: wk
+    path: ` + ws + `
+    session_preset: nope
+    projects:
+      - name: api
+        session_preset: gone
+        sister_repos:
+          - name: api-web
+            label: web
+      - name: mono
+        sub_path: ../elsewhere
+`,
+			want: []string{
+				`line 1: default_workspace: workspace "home" isn't defined`,
+				`line 6: workspaces.work.session_preset: session preset "nope" isn't defined`,
+				`line 9: workspaces.work.projects.0.session_preset: session preset "gone" isn't defined`,
+				`line 11: workspaces.work.projects.0.sister_repos.0.name: api-web isn't in the workspace folder`,
+				`line 14: workspaces.work.projects.1.sub_path: ../elsewhere must be a path inside the project`,
+			},
+		},
+		{
+			name: "Paths_and_prefixes",
+			yaml: `workspaces:
+  home:
+    prefix: wk
+    path: ` + ws + `
+  work:
+    prefix: wk-api
+    path: /does/not/exist
+  play:
+    prefix: wk
+`,
+			want: []string{
+				`line 6: workspaces.work.prefix: prefix "wk-api" overlaps "wk" of workspace "home"`,
+				`line 6: workspaces.work.prefix: prefix "wk-api" overlaps "wk" of workspace "play"`,
+				`line 7: workspaces.work.path: /does/not/exist doesn't exist`,
+				`line 8: workspaces.play: path is required`,
+				`line 9: workspaces.play.prefix: prefix "wk" is also used by workspace "home"`,
+			},
+		},
+		{
+			name: "Unknown_values",
+			yaml: `default_multiplexer: screen
+workspaces:
+  work:
+    prefix: wk
+    path: ` + ws + `
+    multiplexer: tmuxx
+    github_backend: graphql
+    forge: bitbucket
+    clone_protocol: git
+    session_config:
+      screens:
+        - layout: grid
+          panes:
+            - orientation: diagonal
+`,
+			want: []string{
+				`line 1: default_multiplexer: unknown multiplexer "screen" (want tmux, gtmux or zellij)`,
+				`line 6: workspaces.work.multiplexer: unknown multiplexer "tmuxx" (want tmux, gtmux or zellij)`,
+				`line 7: workspaces.work.github_backend: unknown github backend "graphql" (want api or cli)`,
+				`line 8: workspaces.work.forge: unknown forge "bitbucket" (want github, gitlab or gitea)`,
+				`line 9: workspaces.work.clone_protocol: unknown clone protocol "git" (want ssh or https)`,
+				`line 12: workspaces.work.session_config.screens.0.layout: unknown layout "grid" (want one of even-horizontal, even-vertical, main-horizontal, main-horizontal-mirrored, main-vertical, main-vertical-mirrored, tiled)`,
+				`line 14: workspaces.work.session_config.screens.0.panes.0.orientation: unknown orientation "diagonal" (want horizontal or vertical)`,
+			},
+		},
+		{
+			name: "Templates",
+			yaml: `session_presets:
+  dev:
+    screens:
+      - name: "{{.Project}"
+        panes:
+          - command: nvim {{.File}}
+          - command: go run ./cmd/{{.Project | nope}}
+            path: "{{.Sisters.docs}}"
+`,
+			want: []string{
+				`line 4: session_presets.dev.screens.0.name: invalid template: name:1: bad character U+007D '}'`,
+				`line 7: session_presets.dev.screens.0.panes.1.command: invalid template: command:1: function "nope" not defined`,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "workspaces.yaml")
+			require.NoError(t, os.WriteFile(path, []byte(tt.yaml), 0644))
+			conf, err := LoadGlobalConfig(path)
+			require.NoError(t, err)
+
+			var got []string
+			for _, e := range conf.Validate() {
+				got = append(got, e.Error())
+			}
+			assert.Equal(t, tt.want, got)
+			assert.Equal(t, len(tt.want) == 0, conf.IsValid())
+		})
+	}
+}
+
+func TestIsValidLayout(t *testing.T) {
+	assert.True(t, IsValidLayout("tiled"))
+	assert.True(t, IsValidLayout("bb62,159x48,0,0{79x48,0,0,79x48,80,0}"))
+	assert.False(t, IsValidLayout("grid"))
+}
@@ -5,4 +5,6 @@ var (
 	LoadedConfigPath string
 	// LoadedEnvPath stores the path to the actually loaded env file (empty if none loaded)
 	LoadedEnvPath string
+	// DryRun makes session commands print what they would do instead of driving the multiplexer
+	DryRun bool
 )
@@ -0,0 +1,201 @@
+package multiselect
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// Item is one selectable row. Disabled items are shown (with Note) but can't
+// be selected, e.g. repos that are already cloned.
+type Item struct {
+	Display  string
+	Value    string
+	Note     string
+	Disabled bool
+}
+
+var (
+	highlight = lipgloss.AdaptiveColor{Light: "#874BFD", Dark: "#7D56F4"}
+	special   = lipgloss.AdaptiveColor{Light: "#43BF6D", Dark: "#73F59F"}
+	subtle    = lipgloss.AdaptiveColor{Light: "#9B9B9B", Dark: "#5C5C5C"}
+
+	titleStyle    = lipgloss.NewStyle().Foreground(highlight).Bold(true)
+	cursorStyle   = lipgloss.NewStyle().Foreground(highlight).Bold(true)
+	checkedStyle  = lipgloss.NewStyle().Foreground(special)
+	disabledStyle = lipgloss.NewStyle().Foreground(subtle)
+	helpStyle     = lipgloss.NewStyle().Foreground(subtle)
+)
+
+type model struct {
+	title    string
+	items    []Item
+	filter   textinput.Model
+	visible  []int // indexes into items matching the filter
+	cursor   int   // index into visible
+	selected map[int]bool
+	height   int
+
+	confirmed bool
+}
+
+// Run shows the list and blocks until the user confirms or cancels. It returns
+// the Values of the selected items; cancelling returns none.
+func Run(title string, items []Item) ([]string, error) {
+	filter := textinput.New()
+	filter.Prompt = "/ "
+	filter.Placeholder = "filter"
+	filter.Focus()
+
+	m := model{title: title, items: items, filter: filter, selected: map[int]bool{}, height: 20}
+	m.applyFilter()
+
+	final, err := tea.NewProgram(m, tea.WithAltScreen()).Run()
+	if err != nil {
+		return nil, err
+	}
+	fm, ok := final.(model)
+	if !ok || !fm.confirmed {
+		return nil, nil
+	}
+
+	values := []string{}
+	for i, it := range fm.items {
+		if fm.selected[i] {
+			values = append(values, it.Value)
+		}
+	}
+	return values, nil
+}
+
+func (m model) Init() tea.Cmd {
+	return textinput.Blink
+}
+
+func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.height = msg.Height - 6
+		if m.height < 3 {
+			m.height = 3
+		}
+		return m, nil
+	case tea.KeyMsg:
+		switch msg.String() {
+		case "ctrl+c", "esc":
+			return m, tea.Quit
+		case "enter":
+			// Enter with nothing ticked takes the row under the cursor.
+			if len(m.selected) == 0 {
+				m.toggle()
+			}
+			m.confirmed = true
+			return m, tea.Quit
+		case "up", "ctrl+p", "ctrl+k":
+			if m.cursor > 0 {
+				m.cursor--
+			}
+			return m, nil
+		case "down", "ctrl+n", "ctrl+j":
+			if m.cursor < len(m.visible)-1 {
+				m.cursor++
+			}
+			return m, nil
+		case "tab", " ":
+			m.toggle()
+			if m.cursor < len(m.visible)-1 {
+				m.cursor++
+			}
+			return m, nil
+		case "ctrl+a":
+			for _, i := range m.visible {
+				if !m.items[i].Disabled {
+					m.selected[i] = true
+				}
+			}
+			return m, nil
+		}
+	}
+
+	var cmd tea.Cmd
+	before := m.filter.Value()
+	m.filter, cmd = m.filter.Update(msg)
+	if m.filter.Value() != before {
+		m.applyFilter()
+	}
+	return m, cmd
+}
+
+func (m *model) toggle() {
+	if len(m.visible) == 0 {
+		return
+	}
+	i := m.visible[m.cursor]
+	if m.items[i].Disabled {
+		return
+	}
+	if m.selected[i] {
+		delete(m.selected, i)
+	} else {
+		m.selected[i] = true
+	}
+}
+
+func (m *model) applyFilter() {
+	term := strings.ToLower(m.filter.Value())
+	m.visible = m.visible[:0]
+	for i, it := range m.items {
+		if term == "" || strings.Contains(strings.ToLower(it.Display), term) {
+			m.visible = append(m.visible, i)
+		}
+	}
+	if m.cursor >= len(m.visible) {
+		m.cursor = max(len(m.visible)-1, 0)
+	}
+}
+
+func (m model) View() string {
+	var sb strings.Builder
+
+	sb.WriteString(titleStyle.Render(m.title) + "\n")
+	sb.WriteString(m.filter.View() + "\n\n")
+
+	// Scroll so the cursor stays on screen.
+	start := 0
+	if m.cursor >= m.height {
+		start = m.cursor - m.height + 1
+	}
+	end := min(start+m.height, len(m.visible))
+
+	for vi := start; vi < end; vi++ {
+		i := m.visible[vi]
+		it := m.items[i]
+
+		pointer := "  "
+		if vi == m.cursor {
+			pointer = cursorStyle.Render("> ")
+		}
+
+		line := "[ ] " + it.Display
+		switch {
+		case it.Disabled:
+			line = disabledStyle.Render("[-] " + it.Display)
+		case m.selected[i]:
+			line = checkedStyle.Render("[x] " + it.Display)
+		}
+		if it.Note != "" {
+			line += " " + disabledStyle.Render(it.Note)
+		}
+		sb.WriteString(pointer + line + "\n")
+	}
+	if len(m.visible) == 0 {
+		sb.WriteString(disabledStyle.Render("  no matches") + "\n")
+	}
+
+	sb.WriteString("\n" + helpStyle.Render(fmt.Sprintf(
+		"%d selected  |  space/tab select  ctrl+a all  enter confirm  esc cancel", len(m.selected))))
+	return sb.String()
+}
@@ -12,7 +12,6 @@ import (
 	"github.com/JamesTiberiusKirk/workspacer/log"
 	"github.com/JamesTiberiusKirk/workspacer/state"
 	"github.com/joho/godotenv"
-	gotmux "github.com/jubnzv/go-tmux"
 )
 
 const (
@@ -44,26 +43,6 @@ func LoadEnvFile(wc config.WorkspaceConfig) {
 	state.LoadedEnvPath = path // Record which env file was loaded
 }
 
-func GetOpenProjectsByWorkspace(wsPrefix string) []string {
-	server := new(gotmux.Server)
-	sessions, err := server.ListSessions()
-	if err != nil {
-		log.Error("could not get tmux sessions: %s\n",
-			err.Error())
-		return []string{}
-	}
-
-	openProjects := []string{}
-	for _, s := range sessions {
-		if !strings.HasPrefix(s.Name, wsPrefix) {
-			continue
-		}
-		openProjects = append(openProjects, strings.TrimPrefix(s.Name, wsPrefix+"-"))
-	}
-
-	return openProjects
-}
-
 func GetWorkspacePath(wc config.WorkspaceConfig) string {
 	if strings.HasPrefix(wc.Path, "~/") {
 		dirname, _ := os.UserHomeDir()
@@ -198,6 +177,16 @@ func ExpandTilde(path string) (string, error) {
 	return path, nil
 }
 
+// GetProjectConfig returns the projects entry for a project, if it has one
+func GetProjectConfig(wc config.WorkspaceConfig, projectName string) (config.ProjectConfig, bool) {
+	for _, p := range wc.Projects {
+		if p.Name == projectName {
+			return p, true
+		}
+	}
+	return config.ProjectConfig{}, false
+}
+
 // GetSisterReposForProject returns the sister repos configured for a project
 func GetSisterReposForProject(wc config.WorkspaceConfig, projectName string) []config.SisterRepoConfig {
 	for _, p := range wc.Projects {
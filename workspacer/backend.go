@@ -2,15 +2,17 @@ package workspacer
 
 import (
 	"os"
+	"sort"
 	"strings"
 
 	"github.com/JamesTiberiusKirk/workspacer/config"
+	"github.com/JamesTiberiusKirk/workspacer/state"
 	gotmux "github.com/jubnzv/go-tmux"
 )
 
 // SessionBackend is the multiplexer surface workspacer needs. Orchestration
 // (start-or-switch, prefix-kill, sister repos, spec assembly) lives above this;
-// a backend only realizes the primitives. tmux and gtmux each implement it.
+// a backend only realizes the primitives. tmux, gtmux and zellij each implement it.
 type SessionBackend interface {
 	HasSession(name string) bool
 	ListSessions() ([]string, error)
@@ -26,10 +28,30 @@ type SessionBackend interface {
 // SessionSpec is a backend-agnostic description of a session to build.
 type SessionSpec struct {
 	Name    string
-	Path    string // session root; default start-dir for its windows
+	Path    string            // session root; default start-dir for its windows
+	Env     map[string]string // session environment, set before any pane starts
+	Meta    SessionMeta       // workspace/project tags; zero for tmp and preset sessions
 	Windows []WindowSpec
 }
 
+// envList returns Env as KEY=value pairs, sorted by key.
+func (s SessionSpec) envList() []string {
+	list := make([]string, 0, len(s.Env))
+	for _, k := range sortedKeys(s.Env) {
+		list = append(list, k+"="+s.Env[k])
+	}
+	return list
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
 type WindowSpec struct {
 	Name   string
 	Layout string // tmux layout name: even-horizontal, main-vertical, tiled, ...
@@ -39,32 +61,110 @@ type WindowSpec struct {
 
 type PaneSpec struct {
 	Command string // run in the pane; "" = bare shell
-	Size    int    // width percent, 0 = layout default
+	Width   string // "60%" or cells ("120"); "" = layout default
+	Height  string // "30%" or cells ("15"); "" = layout default
+	Path    string // start-dir; "" falls back to the window's
+	// Orientation is how this pane is split off the previous one (ignored for
+	// the first pane): horizontal = to its right, vertical = below it, "" =
+	// the backend's default. A window Layout, when set, rearranges afterwards.
+	Orientation config.Orientation
+}
+
+// windowDir is w's start-dir: its own, else the session root.
+func (s SessionSpec) windowDir(w WindowSpec) string {
+	if w.Path != "" {
+		return w.Path
+	}
+	return s.Path
+}
+
+// paneDir is p's start-dir: its own, else its window's.
+func (s SessionSpec) paneDir(w WindowSpec, p PaneSpec) string {
+	if p.Path != "" {
+		return p.Path
+	}
+	return s.windowDir(w)
+}
+
+// firstPaneDir is where a window is opened, since the first pane comes with it.
+func (s SessionSpec) firstPaneDir(w WindowSpec) string {
+	if len(w.Panes) > 0 {
+		return s.paneDir(w, w.Panes[0])
+	}
+	return s.windowDir(w)
+}
+
+// GetBackend picks the multiplexer for a workspace from its `multiplexer` key
+// (the middleware fills it from `default_multiplexer` when unset). Mirrors
+// GetProvider(wc).
+func GetBackend(wc config.WorkspaceConfig) SessionBackend {
+	return GetBackendByName(wc.Multiplexer)
 }
 
-// GetBackend picks the multiplexer from the WORKSPACER_MUX env var (set it in
-// your profile next to the aliases). Defaults to tmux when unset/unrecognized.
-func GetBackend() SessionBackend {
-	if os.Getenv("WORKSPACER_MUX") == string(config.MuxGtmux) {
-		return newGtmuxBackend()
+// GetBackendByName returns the backend for mux, for callers with no workspace
+// (tmp, from-preset). The WORKSPACER_MUX env var overrides mux when set, so a
+// backend can be tried out without editing the config. Defaults to tmux when
+// unset/unrecognized.
+func GetBackendByName(mux config.MuxBackend) SessionBackend {
+	if env := os.Getenv("WORKSPACER_MUX"); env != "" {
+		mux = config.MuxBackend(env)
+	}
+	return backendFor(mux)
+}
+
+// backendFor returns the backend for mux regardless of WORKSPACER_MUX, for a
+// session whose multiplexer is known, like the one this process runs in.
+func backendFor(mux config.MuxBackend) SessionBackend {
+	if backendOverride != nil {
+		return backendOverride
+	}
+
+	var be SessionBackend
+	switch mux {
+	case config.MuxGtmux:
+		be = newGtmuxBackend()
+	case config.MuxZellij:
+		be = newZellijBackend()
+	default:
+		be = newTmuxBackend()
+	}
+
+	// --dry-run: still read real sessions, but only print what would change.
+	if state.DryRun {
+		return NewRecordingBackend(be, os.Stdout)
 	}
-	return newTmuxBackend()
+	return be
 }
 
+// backendOverride, when set, is returned by GetBackend for every workspace.
+// Tests set it to a RecordingBackend to capture what orchestration builds.
+var backendOverride SessionBackend
+
 // CurrentSessionName reports the session this process is running inside, probing
-// gtmux ($GTMUX = sock,pid,session) first, then tmux. Backend-independent: the
-// middleware needs it before a workspace (hence a backend) is known.
+// gtmux ($GTMUX = sock,pid,session) first, then zellij ($ZELLIJ_SESSION_NAME),
+// then tmux. Backend-independent: the middleware needs it before a workspace
+// (hence a backend) is known.
 func CurrentSessionName() (string, bool) {
+	name, _, ok := currentSession()
+	return name, ok
+}
+
+// currentSession is CurrentSessionName plus the multiplexer the session
+// belongs to.
+func currentSession() (string, config.MuxBackend, bool) {
 	if g := os.Getenv("GTMUX"); g != "" {
 		parts := strings.Split(g, ",")
 		if len(parts) >= 3 && parts[2] != "" {
-			return parts[2], true
+			return parts[2], config.MuxGtmux, true
 		}
 	}
+	if name := os.Getenv("ZELLIJ_SESSION_NAME"); name != "" {
+		return name, config.MuxZellij, true
+	}
 	if os.Getenv("TMUX") != "" {
 		if name, err := gotmux.GetAttachedSessionName(); err == nil && name != "" {
-			return name, true
+			return name, config.MuxTmux, true
 		}
 	}
-	return "", false
+	return "", "", false
 }
@@ -0,0 +1,81 @@
+package workspacer
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/JamesTiberiusKirk/workspacer/config"
+	"github.com/JamesTiberiusKirk/workspacer/util"
+)
+
+// RepoFilter selects the repos bootstrap clones. Empty fields match every
+// repo; archived repos are skipped unless Archived is set.
+type RepoFilter struct {
+	Topics    []string       // has at least one of these topics
+	Languages []string       // primary language is one of these, case-insensitive
+	Name      *regexp.Regexp // name matches
+	Archived  bool           // include archived repos
+}
+
+// Match reports whether r passes every set filter.
+func (f RepoFilter) Match(r Repo) bool {
+	if r.Archived && !f.Archived {
+		return false
+	}
+	if f.Name != nil && !f.Name.MatchString(r.Name) {
+		return false
+	}
+	if len(f.Languages) > 0 && !slices.ContainsFunc(f.Languages, func(l string) bool {
+		return strings.EqualFold(l, r.Language)
+	}) {
+		return false
+	}
+	if len(f.Topics) > 0 && !slices.ContainsFunc(f.Topics, func(t string) bool {
+		return slices.Contains(r.Topics, t)
+	}) {
+		return false
+	}
+	return true
+}
+
+// BootstrapPlan is what bootstrap will do: the matching repos split into
+// those still to clone and those already in the workspace.
+type BootstrapPlan struct {
+	Missing []string
+	Present []string
+}
+
+// PlanBootstrap fetches every repo of the workspace's org/user and sorts the
+// ones matching filter into missing and already present, so bootstrap can be
+// re-run safely.
+func PlanBootstrap(wc config.WorkspaceConfig, filter RepoFilter) (BootstrapPlan, error) {
+	if wc.GithubOrg == "" {
+		return BootstrapPlan{}, fmt.Errorf("workspace %s has no org_github set", wc.Name)
+	}
+
+	repos, err := GetForge(wc).GetRepos(wc.GithubOrg, wc.IsOrg)
+	if err != nil {
+		return BootstrapPlan{}, fmt.Errorf("failed to fetch repositories: %w", err)
+	}
+	return planBootstrap(wc, repos, filter), nil
+}
+
+func planBootstrap(wc config.WorkspaceConfig, repos []Repo, filter RepoFilter) BootstrapPlan {
+	plan := BootstrapPlan{Missing: []string{}, Present: []string{}}
+	for _, r := range repos {
+		if !filter.Match(r) {
+			continue
+		}
+		if util.DoesProjectExist(wc, r.Name) {
+			plan.Present = append(plan.Present, r.Name)
+		} else {
+			plan.Missing = append(plan.Missing, r.Name)
+		}
+	}
+	sort.Strings(plan.Missing)
+	sort.Strings(plan.Present)
+	return plan
+}
@@ -0,0 +1,47 @@
+package workspacer
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPlanBootstrap(t *testing.T) {
+	wc := testWorkspace(t, "billing-api")
+	repos := []Repo{
+		{Name: "billing-api", Language: "Go", Topics: []string{"service"}},
+		{Name: "orders-api", Language: "Go", Topics: []string{"service", "orders"}},
+		{Name: "web", Language: "TypeScript", Topics: []string{"frontend"}},
+		{Name: "legacy-api", Language: "Go", Topics: []string{"service"}, Archived: true},
+		{Name: "docs"},
+	}
+
+	tests := []struct {
+		name   string
+		filter RepoFilter
+		want   BootstrapPlan
+	}{
+		{
+			name:   "Everything_but_archived",
+			filter: RepoFilter{},
+			want:   BootstrapPlan{Missing: []string{"docs", "orders-api", "web"}, Present: []string{"billing-api"}},
+		},
+		{
+			name:   "Archived_included",
+			filter: RepoFilter{Archived: true, Name: regexp.MustCompile(`-api$`)},
+			want:   BootstrapPlan{Missing: []string{"legacy-api", "orders-api"}, Present: []string{"billing-api"}},
+		},
+		{
+			name:   "Topic_and_language",
+			filter: RepoFilter{Topics: []string{"orders", "frontend"}, Languages: []string{"go"}},
+			want:   BootstrapPlan{Missing: []string{"orders-api"}, Present: []string{}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, planBootstrap(wc, repos, tt.filter))
+		})
+	}
+}
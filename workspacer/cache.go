@@ -26,6 +26,13 @@ type SisterCache struct {
 type ProjectCache struct {
 	GitBranch         string                 `json:"git_branch,omitempty"`
 	GitChanges        int                    `json:"git_changes,omitempty"`
+	GitAhead          int                    `json:"git_ahead,omitempty"`
+	GitBehind         int                    `json:"git_behind,omitempty"`
+	GitHasUpstream    bool                   `json:"git_has_upstream,omitempty"`
+	GitStashes        int                    `json:"git_stashes,omitempty"`
+	GitLastCommit     *time.Time             `json:"git_last_commit,omitempty"`
+	GitDetached       bool                   `json:"git_detached,omitempty"`
+	GitRebasing       bool                   `json:"git_rebasing,omitempty"`
 	SisterRepos       map[string]SisterCache `json:"sister_repos,omitempty"`
 	AccessCountTotal  int                    `json:"access_count_total"`
 	AccessCountRecent int                    `json:"access_count_recent"`
@@ -122,6 +129,16 @@ func (c *WorkspaceCache) UpdateGitInfo(projectName string, info repoGitInfo) {
 
 	project.GitBranch = info.branch
 	project.GitChanges = info.changesCount
+	project.GitAhead = info.state.ahead
+	project.GitBehind = info.state.behind
+	project.GitHasUpstream = info.state.hasUpstream
+	project.GitStashes = info.state.stashes
+	project.GitLastCommit = nil
+	if !info.state.lastCommit.IsZero() {
+		project.GitLastCommit = &info.state.lastCommit
+	}
+	project.GitDetached = info.state.detached
+	project.GitRebasing = info.state.rebasing
 
 	if len(info.sisters) > 0 {
 		project.SisterRepos = make(map[string]SisterCache, len(info.sisters))
@@ -138,6 +155,22 @@ func (c *WorkspaceCache) UpdateGitInfo(projectName string, info repoGitInfo) {
 	c.Projects[projectName] = project
 }
 
+// gitState is the cached git state of the project.
+func (p ProjectCache) gitState() gitState {
+	s := gitState{
+		ahead:       p.GitAhead,
+		behind:      p.GitBehind,
+		hasUpstream: p.GitHasUpstream,
+		stashes:     p.GitStashes,
+		detached:    p.GitDetached,
+		rebasing:    p.GitRebasing,
+	}
+	if p.GitLastCommit != nil {
+		s.lastCommit = *p.GitLastCommit
+	}
+	return s
+}
+
 // UpdateGithubRepos updates the GitHub repos list in the cache
 func (c *WorkspaceCache) UpdateGithubRepos(repos []string, showArchived bool) {
 	c.GithubRepos = repos
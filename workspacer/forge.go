@@ -0,0 +1,158 @@
+package workspacer
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+
+	"github.com/JamesTiberiusKirk/workspacer/config"
+)
+
+// Forge is the code host a workspace's repos live on. Repo listing comes
+// from its GitHubProvider methods; the rest is what cloning, `new --gh` and
+// `actions` need. GitHub (the default), GitLab and Gitea/Forgejo implement
+// it, picked per workspace with `forge`. Mirrors
+// GetProvider/GetBackend.
+type Forge interface {
+	GitHubProvider
+	// Name is the forge's display name, e.g. in the picker.
+	Name() string
+	// Host is the forge's git host (e.g. github.com), used in clone URLs and
+	// Go module paths.
+	Host() string
+	// CloneURLs returns the SSH and HTTPS clone URLs of owner/repo.
+	CloneURLs(owner, repo string) (ssh, https string)
+	// CreateRepo creates name under owner (an org/group when isOrg, else the
+	// authenticated user) and returns the created repo's name.
+	CreateRepo(owner string, isOrg bool, name string, private bool) (string, error)
+	// PipelineStatus returns "<branch> <emoji>" for each branch whose latest
+	// CI run could be found.
+	PipelineStatus(owner, repo string, branches ...string) []string
+}
+
+// GetForge returns the forge for a workspace.
+func GetForge(wc config.WorkspaceConfig) Forge {
+	switch wc.Forge {
+	case config.ForgeGitLab:
+		return NewGitLabForge(wc.ForgeURL, os.Getenv(forgeTokenEnv(wc, "GITLAB_TOKEN")))
+	case config.ForgeGitea:
+		return NewGiteaForge(wc.ForgeURL, os.Getenv(forgeTokenEnv(wc, "GITEA_TOKEN")))
+	case config.ForgeGitHub:
+		fallthrough
+	default:
+		return &GitHubForge{GitHubProvider: GetProvider(wc), GitHub: GetGitHubHost(wc)}
+	}
+}
+
+// forgeTokenEnv is the env var holding the workspace's forge token:
+// forge_token_env, else the forge's usual one.
+func forgeTokenEnv(wc config.WorkspaceConfig, fallback string) string {
+	if wc.ForgeTokenEnv != "" {
+		return wc.ForgeTokenEnv
+	}
+	return fallback
+}
+
+// RepoCloneURL is the URL repo is cloned from and pushed to: the workspace's
+// clone_url template when set, else the forge's SSH or HTTPS URL per
+// clone_protocol. The template's {host}, {owner} and {repo} are the forge
+// host, org_github and the repo name, e.g.
+// "ssh://git@{host}:2222/{owner}/{repo}.git" or, for an SSH host alias,
+// "git@github-work:{owner}/{repo}.git".
+func RepoCloneURL(wc config.WorkspaceConfig, repo string) string {
+	forge := GetForge(wc)
+	if wc.CloneURL == "" {
+		ssh, https := forge.CloneURLs(wc.GithubOrg, repo)
+		if wc.CloneProtocol == config.CloneHTTPS {
+			return https
+		}
+		return ssh
+	}
+	return strings.NewReplacer(
+		"{host}", forge.Host(),
+		"{owner}", wc.GithubOrg,
+		"{repo}", repo,
+	).Replace(wc.CloneURL)
+}
+
+// emojiForStatus renders a CI status the way `actions` prints it.
+func emojiForStatus(status string) string {
+	switch status {
+	case "success":
+		return "🟢"
+	case "in_progress", "running", "pending", "created", "waiting_for_resource", "preparing", "warning":
+		return "🟡"
+	default:
+		return "🔴"
+	}
+}
+
+// forgeRequest sends a JSON request to a forge's REST API (apiURL + path) and
+// decodes a JSON response into out. name labels errors.
+func forgeRequest(client *http.Client, name, method, apiURL, path string, query url.Values, header http.Header, body, out any) (*http.Response, error) {
+	u := apiURL + path
+	if len(query) > 0 {
+		u += "?" + query.Encode()
+	}
+
+	var reqBody io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return nil, err
+		}
+		reqBody = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequest(method, u, reqBody)
+	if err != nil {
+		return nil, err
+	}
+	for k, v := range header {
+		req.Header[k] = v
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("%s API %s %s: %w", name, method, path, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		msg, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("%s API %s %s: %s: %s", name, method, path, resp.Status, strings.TrimSpace(string(msg)))
+	}
+	if out != nil {
+		if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+			return nil, fmt.Errorf("%s API %s %s: failed to parse response: %w", name, method, path, err)
+		}
+	}
+	return resp, nil
+}
+
+// sshHost is host without its port: scp-style git@host:path URLs can't carry
+// one, and SSH doesn't listen on the web port anyway.
+func sshHost(host string) string {
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		return h
+	}
+	return host
+}
+
+// hostOf returns the host of a forge base URL, with its port if it has one.
+func hostOf(baseURL string) string {
+	u, err := url.Parse(baseURL)
+	if err != nil || u.Host == "" {
+		return strings.TrimPrefix(strings.TrimPrefix(baseURL, "https://"), "http://")
+	}
+	return u.Host
+}
@@ -5,19 +5,28 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
 
 	"github.com/JamesTiberiusKirk/workspacer/config"
 	"github.com/JamesTiberiusKirk/workspacer/util"
 )
 
 func CloneRepo(wc config.WorkspaceConfig, repoName string) error {
+	return cloneRepo(wc, repoName, true)
+}
+
+// cloneRepo clones repoName into the workspace. verbose streams git's output
+// and progress lines to the terminal; otherwise git runs quietly and its
+// output only ends up in the error, so parallel clones don't interleave.
+func cloneRepo(wc config.WorkspaceConfig, repoName string, verbose bool) error {
 	parentFolder, err := util.ExpandTilde(wc.Path)
 	if err != nil {
 		return fmt.Errorf("failed to expand workspace path: %w", err)
 	}
 
-	githubUserOrOrg := wc.GithubOrg
-	repoURL := fmt.Sprintf("git@github.com:%s/%s.git", githubUserOrOrg, repoName)
+	repoURL := RepoCloneURL(wc, repoName)
 	clonePath := filepath.Join(parentFolder, repoName)
 
 	// Check if destination directory already exists
@@ -32,8 +41,18 @@ func CloneRepo(wc config.WorkspaceConfig, repoName string) error {
 		return fmt.Errorf("git is not installed or not in PATH: %w", err)
 	}
 
+	args := append([]string{"clone"}, cloneArgs(wc.CloneOptions)...)
+	if !verbose {
+		args = append(args, "--quiet", repoURL, clonePath)
+		out, err := exec.Command("git", args...).CombinedOutput()
+		if err != nil {
+			return fmt.Errorf("failed to clone repository: %w: %s", err, strings.TrimSpace(string(out)))
+		}
+		return nil
+	}
+
 	// Run git clone
-	cmd := exec.Command("git", "clone", repoURL, clonePath)
+	cmd := exec.Command("git", append(args, repoURL, clonePath)...)
 	cmd.Stdout = os.Stdout
 	cmd.Stderr = os.Stderr
 
@@ -46,6 +65,52 @@ func CloneRepo(wc config.WorkspaceConfig, repoName string) error {
 	return nil
 }
 
+// cloneArgs turns the workspace's clone_options into git clone flags.
+func cloneArgs(o config.CloneOptions) []string {
+	var args []string
+	if o.Depth > 0 {
+		args = append(args, "--depth", strconv.Itoa(o.Depth))
+	}
+	if o.Filter != "" {
+		args = append(args, "--filter="+o.Filter)
+	}
+	if o.RecurseSubmodules {
+		args = append(args, "--recurse-submodules")
+	}
+	return append(args, o.Args...)
+}
+
+// CloneResult is the outcome of cloning one repo with CloneRepos.
+type CloneResult struct {
+	Repo string
+	Err  error
+}
+
+// CloneRepos clones repos into the workspace concurrently, at most workers at
+// a time. onDone, when set, is called (from one goroutine at a time) as each
+// clone finishes, with the number finished so far. Results are returned in
+// the order of repos.
+func CloneRepos(wc config.WorkspaceConfig, repos []string, workers int, onDone func(done int, r CloneResult)) []CloneResult {
+	results := make([]CloneResult, len(repos))
+	var (
+		mu   sync.Mutex
+		done int
+	)
+	parallel(len(repos), workers, func(i int) {
+		r := CloneResult{Repo: repos[i], Err: cloneRepo(wc, repos[i], false)}
+
+		mu.Lock()
+		results[i] = r
+		done++
+		if onDone != nil {
+			onDone(done, r)
+		}
+		mu.Unlock()
+	})
+
+	return results
+}
+
 func NewProjectAndPush(wc config.WorkspaceConfig, repoName string) error {
 	parentFolder, err := util.ExpandTilde(wc.Path)
 	if err != nil {
@@ -68,7 +133,7 @@ func NewProjectAndPush(wc config.WorkspaceConfig, repoName string) error {
 	fmt.Printf("Initialised git repo\n")
 
 	// Derive go module path
-	modulePath := fmt.Sprintf("github.com/%s/%s", wc.GithubOrg, repoName)
+	modulePath := fmt.Sprintf("%s/%s/%s", GetForge(wc).Host(), wc.GithubOrg, repoName)
 	if _, err := util.ExecCmd(repoPath, "go", "mod", "init", modulePath); err != nil {
 		return fmt.Errorf("go mod init failed: %w", err)
 	}
@@ -92,16 +157,16 @@ func NewProjectAndPush(wc config.WorkspaceConfig, repoName string) error {
 
 	fmt.Printf("Created initial commit\n")
 
-	sshURL := fmt.Sprintf("git@github.com:%s/%s.git", wc.GithubOrg, repoName)
+	remoteURL := RepoCloneURL(wc, repoName)
 
-	if _, err := util.ExecCmd("", "git", "-C", repoPath, "remote", "add", "origin", sshURL); err != nil {
+	if _, err := util.ExecCmd("", "git", "-C", repoPath, "remote", "add", "origin", remoteURL); err != nil {
 		return fmt.Errorf("git remote add failed: %w", err)
 	}
 	if _, err := util.ExecCmd("", "git", "-C", repoPath, "push", "-u", "--force", "origin", "master"); err != nil {
 		return fmt.Errorf("git push failed: %w", err)
 	}
 
-	fmt.Println("Repository created and pushed to GitHub:", sshURL)
+	fmt.Printf("Repository created and pushed to %s: %s\n", GetForge(wc).Name(), remoteURL)
 	return nil
 }
 
@@ -127,7 +192,7 @@ func NewProject(wc config.WorkspaceConfig, repoName string) error {
 	fmt.Printf("Initialised git repo\n")
 
 	// Derive go module path
-	modulePath := fmt.Sprintf("github.com/%s/%s", wc.GithubOrg, repoName)
+	modulePath := fmt.Sprintf("%s/%s/%s", GetForge(wc).Host(), wc.GithubOrg, repoName)
 	if _, err := util.ExecCmd(repoPath, "go", "mod", "init", modulePath); err != nil {
 		return fmt.Errorf("go mod init failed: %w", err)
 	}
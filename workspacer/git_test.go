@@ -0,0 +1,100 @@
+package workspacer
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/JamesTiberiusKirk/workspacer/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCloneRepos(t *testing.T) {
+	// Existing projects fail before git runs, so no network is needed.
+	wc := testWorkspace(t, "api", "web", "infra")
+	wc.GithubOrg = "acme"
+
+	var progress []int
+	results := CloneRepos(wc, []string{"api", "web", "infra"}, 2, func(done int, r CloneResult) {
+		progress = append(progress, done)
+	})
+
+	require.Len(t, results, 3)
+	for i, repo := range []string{"api", "web", "infra"} {
+		assert.Equal(t, repo, results[i].Repo)
+		assert.ErrorContains(t, results[i].Err, "already exists")
+	}
+	assert.Equal(t, []int{1, 2, 3}, progress)
+}
+
+func TestRepoCloneURL(t *testing.T) {
+	tests := []struct {
+		name string
+		wc   config.WorkspaceConfig
+		want string
+	}{
+		{
+			name: "Ssh_by_default",
+			wc:   config.WorkspaceConfig{GithubOrg: "acme"},
+			want: "git@github.com:acme/api.git",
+		},
+		{
+			name: "Https",
+			wc:   config.WorkspaceConfig{GithubOrg: "acme", CloneProtocol: config.CloneHTTPS},
+			want: "https://github.com/acme/api.git",
+		},
+		{
+			name: "Ssh_host_alias_template",
+			wc:   config.WorkspaceConfig{GithubOrg: "acme", CloneURL: "git@github-work:{owner}/{repo}.git"},
+			want: "git@github-work:acme/api.git",
+		},
+		{
+			name: "Mirror_template",
+			wc:   config.WorkspaceConfig{GithubOrg: "acme", GithubHost: "github.corp.com", CloneURL: "https://mirror.corp.com/{host}/{owner}/{repo}"},
+			want: "https://mirror.corp.com/github.corp.com/acme/api",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, RepoCloneURL(tt.wc, "api"))
+		})
+	}
+}
+
+// gitRun runs git in dir with a throwaway identity and returns its trimmed
+// output, failing the test on error.
+func gitRun(t testing.TB, dir string, args ...string) string {
+	t.Helper()
+	out, err := exec.Command("git", append([]string{"-C", dir, "-c", "user.name=t", "-c", "user.email=t@t"}, args...)...).CombinedOutput()
+	require.NoError(t, err, string(out))
+	return strings.TrimSpace(string(out))
+}
+
+// initRepo makes dir a git repo with commits empty commits on main.
+func initRepo(t testing.TB, dir string, commits int) {
+	t.Helper()
+	require.NoError(t, os.MkdirAll(dir, 0755))
+	gitRun(t, dir, "init", "--quiet", "--initial-branch=main")
+	for i := 0; i < commits; i++ {
+		gitRun(t, dir, "commit", "--quiet", "--allow-empty", "-m", "commit")
+	}
+}
+
+func TestCloneRepoOptions(t *testing.T) {
+	// A local "remote" with two commits, cloned through a clone_url template.
+	remote := filepath.Join(t.TempDir(), "acme", "api")
+	initRepo(t, remote, 2)
+
+	wc := testWorkspace(t)
+	wc.GithubOrg = "acme"
+	wc.CloneURL = "file://" + filepath.Dir(filepath.Dir(remote)) + "/{owner}/{repo}"
+	wc.CloneOptions = config.CloneOptions{Depth: 1, Args: []string{"--single-branch"}}
+
+	assert.Equal(t, []string{"--depth", "1", "--single-branch"}, cloneArgs(wc.CloneOptions))
+	require.NoError(t, cloneRepo(wc, "api", false))
+	assert.Equal(t, "1", gitRun(t, filepath.Join(wc.Path, "api"), "rev-list", "--count", "HEAD"))
+}
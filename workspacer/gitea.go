@@ -0,0 +1,161 @@
+package workspacer
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+const (
+	defaultGiteaURL = "https://codeberg.org"
+	giteaPageSize   = 50
+)
+
+// GiteaForge is the Forge for Gitea and Forgejo instances (including
+// codeberg.org), talking to their REST API (v1). Owners are org or user names.
+type GiteaForge struct {
+	BaseURL string // e.g. https://git.example.com, without /api/v1
+	Token   string // access token; "" for public data only
+	Client  *http.Client
+}
+
+// NewGiteaForge returns a Gitea forge for baseURL ("" = codeberg.org).
+func NewGiteaForge(baseURL, token string) *GiteaForge {
+	if baseURL == "" {
+		baseURL = defaultGiteaURL
+	}
+	return &GiteaForge{
+		BaseURL: strings.TrimSuffix(baseURL, "/"),
+		Token:   token,
+		Client:  http.DefaultClient,
+	}
+}
+
+// giteaRepo is the part of the repos API response workspacer reads.
+type giteaRepo struct {
+	Name     string    `json:"name"`
+	Owner    giteaUser `json:"owner"`
+	Archived bool      `json:"archived"`
+	Language string    `json:"language"`
+	Topics   []string  `json:"topics"`
+}
+
+// giteaUser is the part of a user object workspacer reads.
+type giteaUser struct {
+	Login string `json:"login"`
+}
+
+func (f *GiteaForge) GetRepoNames(login string, isOrg bool, showArchived bool) ([]string, error) {
+	repos, err := f.GetRepos(login, isOrg)
+	if err != nil {
+		return nil, err
+	}
+
+	var names []string
+	for _, r := range repos {
+		if !showArchived && r.Archived {
+			continue
+		}
+		names = append(names, r.Name)
+	}
+	return names, nil
+}
+
+// GetRepos lists the org's (isOrg) or user's repos. /users/{login}/repos
+// only has public repos, so the token's own user is listed through
+// /user/repos, keeping only the repos it owns. Gitea has no next page header,
+// so paging stops at the first short page.
+func (f *GiteaForge) GetRepos(login string, isOrg bool) ([]Repo, error) {
+	path := "/users/" + url.PathEscape(login) + "/repos"
+	owned := false
+	switch {
+	case isOrg:
+		path = "/orgs/" + url.PathEscape(login) + "/repos"
+	case f.isTokenOwner(login):
+		path = "/user/repos"
+		owned = true
+	}
+
+	var repos []Repo
+	for page := 1; ; page++ {
+		var list []giteaRepo
+		query := url.Values{"limit": {strconv.Itoa(giteaPageSize)}, "page": {strconv.Itoa(page)}}
+		if _, err := f.do(http.MethodGet, path, query, nil, &list); err != nil {
+			return nil, err
+		}
+		for _, r := range list {
+			if owned && !strings.EqualFold(r.Owner.Login, login) {
+				continue
+			}
+			repos = append(repos, Repo{Name: r.Name, Archived: r.Archived, Language: r.Language, Topics: r.Topics})
+		}
+		if len(list) < giteaPageSize {
+			return repos, nil
+		}
+	}
+}
+
+// isTokenOwner reports whether login is the user the token belongs to.
+func (f *GiteaForge) isTokenOwner(login string) bool {
+	if f.Token == "" {
+		return false
+	}
+	var user giteaUser
+	if _, err := f.do(http.MethodGet, "/user", nil, nil, &user); err != nil {
+		return false
+	}
+	return strings.EqualFold(user.Login, login)
+}
+
+func (f *GiteaForge) Name() string { return "Gitea" }
+
+func (f *GiteaForge) Host() string { return hostOf(f.BaseURL) }
+
+func (f *GiteaForge) CloneURLs(owner, repo string) (string, string) {
+	return fmt.Sprintf("git@%s:%s/%s.git", sshHost(f.Host()), owner, repo),
+		fmt.Sprintf("%s/%s/%s.git", f.BaseURL, owner, repo)
+}
+
+// CreateRepo creates a repo in the owner org, or for the token's user when
+// !isOrg.
+func (f *GiteaForge) CreateRepo(owner string, isOrg bool, name string, private bool) (string, error) {
+	path := "/user/repos"
+	if isOrg {
+		path = "/orgs/" + url.PathEscape(owner) + "/repos"
+	}
+
+	var created giteaRepo
+	body := map[string]any{"name": name, "private": private}
+	if _, err := f.do(http.MethodPost, path, nil, body, &created); err != nil {
+		return "", fmt.Errorf("failed to create repo: %w", err)
+	}
+	return created.Name, nil
+}
+
+// PipelineStatus reads the combined commit status of each branch head, which
+// Gitea/Forgejo Actions and external CI both report into.
+func (f *GiteaForge) PipelineStatus(owner, repo string, branches ...string) []string {
+	result := []string{}
+	for _, branch := range branches {
+		var status struct {
+			State string `json:"state"`
+		}
+		path := fmt.Sprintf("/repos/%s/%s/commits/%s/status", url.PathEscape(owner), url.PathEscape(repo), url.PathEscape(branch))
+		if _, err := f.do(http.MethodGet, path, nil, nil, &status); err != nil || status.State == "" {
+			continue
+		}
+		result = append(result, branch+" "+emojiForStatus(status.State))
+	}
+	return result
+}
+
+// do sends a request to the API and decodes a JSON response into out.
+func (f *GiteaForge) do(method, path string, query url.Values, body, out any) (*http.Response, error) {
+	header := http.Header{}
+	if f.Token != "" {
+		header.Set("Authorization", "token "+f.Token)
+	}
+	return forgeRequest(f.Client, "Gitea", method, f.BaseURL+"/api/v1", path, query, header, body, out)
+}
@@ -0,0 +1,125 @@
+package workspacer
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeGitea stands in for the Gitea REST API: a full page plus one more repo
+// in the acme org, the token's user kirk with a private repo, repo creation
+// and commit statuses.
+func fakeGitea(t *testing.T) (*httptest.Server, *map[string]any) {
+	t.Helper()
+	created := map[string]any{}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /api/v1/orgs/acme/repos", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "50", r.URL.Query().Get("limit"))
+		var repos []giteaRepo
+		switch r.URL.Query().Get("page") {
+		case "1":
+			for i := range giteaPageSize {
+				repos = append(repos, giteaRepo{Name: fmt.Sprintf("svc-%02d", i), Language: "Go"})
+			}
+		case "2":
+			repos = []giteaRepo{{Name: "old", Archived: true, Topics: []string{"legacy"}}}
+		}
+		json.NewEncoder(w).Encode(repos)
+	})
+	mux.HandleFunc("GET /api/v1/user", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"login": "Kirk"}`))
+	})
+	mux.HandleFunc("GET /api/v1/user/repos", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`[{"name": "dotfiles", "owner": {"login": "Kirk"}}, {"name": "svc-00", "owner": {"login": "acme"}}]`))
+	})
+	mux.HandleFunc("GET /api/v1/users/{login}/repos", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`[{"name": "public-` + r.PathValue("login") + `"}]`))
+	})
+	mux.HandleFunc("POST /api/v1/orgs/acme/repos", func(w http.ResponseWriter, r *http.Request) {
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&created))
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte(`{"name": "new-svc"}`))
+	})
+	mux.HandleFunc("GET /api/v1/repos/acme/api/commits/{ref}/status", func(w http.ResponseWriter, r *http.Request) {
+		switch r.PathValue("ref") {
+		case "main":
+			w.Write([]byte(`{"state": "success"}`))
+		case "staging":
+			w.Write([]byte(`{"state": "failure"}`))
+		case "dev":
+			w.Write([]byte(`{"state": "warning"}`))
+		default:
+			w.Write([]byte(`{"state": ""}`))
+		}
+	})
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "token secret" {
+			http.Error(w, `{"message": "token is required"}`, http.StatusUnauthorized)
+			return
+		}
+		mux.ServeHTTP(w, r)
+	}))
+	t.Cleanup(srv.Close)
+	return srv, &created
+}
+
+func TestGiteaForge(t *testing.T) {
+	srv, created := fakeGitea(t)
+	f := NewGiteaForge(srv.URL+"/", "secret")
+
+	t.Run("Lists_org_repos_across_pages", func(t *testing.T) {
+		repos, err := f.GetRepos("acme", true)
+		require.NoError(t, err)
+		require.Len(t, repos, giteaPageSize+1)
+		assert.Equal(t, Repo{Name: "svc-00", Language: "Go"}, repos[0])
+		assert.Equal(t, Repo{Name: "old", Archived: true, Topics: []string{"legacy"}}, repos[giteaPageSize])
+
+		names, err := f.GetRepoNames("acme", true, false)
+		require.NoError(t, err)
+		assert.Len(t, names, giteaPageSize)
+		assert.NotContains(t, names, "old")
+	})
+
+	t.Run("Lists_token_owners_private_repos", func(t *testing.T) {
+		names, err := f.GetRepoNames("kirk", false, false)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"dotfiles"}, names, "only the repos the user owns")
+
+		names, err = f.GetRepoNames("spock", false, false)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"public-spock"}, names)
+	})
+
+	t.Run("Clone_urls", func(t *testing.T) {
+		ssh, https := f.CloneURLs("acme", "api")
+		assert.Equal(t, "git@127.0.0.1:acme/api.git", ssh, "the web port isn't used for SSH")
+		assert.Equal(t, srv.URL+"/acme/api.git", https)
+
+		ssh, https = NewGiteaForge("https://git.example.com:3000", "").CloneURLs("acme", "api")
+		assert.Equal(t, "git@git.example.com:acme/api.git", ssh)
+		assert.Equal(t, "https://git.example.com:3000/acme/api.git", https)
+	})
+
+	t.Run("Creates_repo_in_org", func(t *testing.T) {
+		name, err := f.CreateRepo("acme", true, "new-svc", true)
+		require.NoError(t, err)
+		assert.Equal(t, "new-svc", name)
+		assert.Equal(t, map[string]any{"name": "new-svc", "private": true}, *created)
+	})
+
+	t.Run("Pipeline_status", func(t *testing.T) {
+		assert.Equal(t, []string{"main 🟢", "staging 🔴", "dev 🟡"}, f.PipelineStatus("acme", "api", "main", "staging", "dev", "feature"))
+	})
+
+	t.Run("Reports_api_errors", func(t *testing.T) {
+		_, err := NewGiteaForge(srv.URL, "wrong").GetRepos("acme", true)
+		assert.ErrorContains(t, err, "401")
+	})
+}
@@ -6,6 +6,7 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+	"sync"
 
 	"github.com/JamesTiberiusKirk/workspacer/config"
 	"github.com/JamesTiberiusKirk/workspacer/log"
@@ -14,19 +15,82 @@ import (
 	"github.com/google/go-github/v66/github"
 )
 
-var ghClient *github.Client
+const defaultGitHubHost = "github.com"
 
-func newGitHubClient() *github.Client {
-	if ghClient != nil {
-		return ghClient
+// GitHubHost is the GitHub instance a workspace talks to: github.com, or a
+// GitHub Enterprise Server set with github_host/github_api_url.
+type GitHubHost struct {
+	Host     string // git and web host, e.g. github.example.com
+	APIURL   string // REST API base, "" for api.github.com
+	Token    string
+	TokenEnv string // the env var Token was read from
+}
+
+// GetGitHubHost returns the workspace's GitHub instance. github_api_url
+// defaults to https://<github_host>/api/v3/, and github_host to the API
+// URL's host when only that is set. The token is read from GITHUB_AUTH, or
+// from the env var named in forge_token_env.
+func GetGitHubHost(wc config.WorkspaceConfig) GitHubHost {
+	h := GitHubHost{
+		Host:     wc.GithubHost,
+		APIURL:   wc.GithubAPIURL,
+		TokenEnv: forgeTokenEnv(wc, "GITHUB_AUTH"),
 	}
-	ghAuth := os.Getenv("GITHUB_AUTH")
-	if ghAuth != "" {
-		ghClient = github.NewClient(nil).WithAuthToken(ghAuth)
-	} else {
-		ghClient = github.NewClient(nil)
+	h.Token = os.Getenv(h.TokenEnv)
+	if h.Host == "" && h.APIURL != "" {
+		h.Host = strings.TrimPrefix(hostOf(h.APIURL), "api.")
+	}
+	if h.Host == "" {
+		h.Host = defaultGitHubHost
+	}
+	if h.APIURL == "" && h.IsEnterprise() {
+		h.APIURL = "https://" + h.Host + "/api/v3/"
+	}
+	return h
+}
+
+// IsEnterprise reports whether h is a GitHub Enterprise Server.
+func (h GitHubHost) IsEnterprise() bool {
+	return h.Host != defaultGitHubHost
+}
+
+// GraphQLURL is the GraphQL endpoint next to the REST API: /api/graphql on
+// GHES, api.github.com/graphql otherwise.
+func (h GitHubHost) GraphQLURL() string {
+	if h.APIURL == "" {
+		return "https://api.github.com/graphql"
+	}
+	return strings.TrimSuffix(strings.TrimSuffix(h.APIURL, "/"), "/v3") + "/graphql"
+}
+
+// ghClients caches a REST client per GitHub instance. Clones and sync reach
+// it from several goroutines, hence the lock.
+var (
+	ghClientsMu sync.Mutex
+	ghClients   = map[GitHubHost]*github.Client{}
+)
+
+func newGitHubClient(h GitHubHost) *github.Client {
+	ghClientsMu.Lock()
+	defer ghClientsMu.Unlock()
+	if c, ok := ghClients[h]; ok {
+		return c
 	}
-	return ghClient
+	c := github.NewClient(nil)
+	if h.APIURL != "" {
+		uploadURL := strings.TrimSuffix(strings.TrimSuffix(h.APIURL, "/"), "/v3") + "/uploads/"
+		enterprise, err := c.WithEnterpriseURLs(h.APIURL, uploadURL)
+		if err != nil {
+			log.Error("Invalid github_api_url %s: %s", h.APIURL, err.Error())
+		} else {
+			c = enterprise
+		}
+	}
+	if h.Token != "" {
+		c = c.WithAuthToken(h.Token)
+	}
+	ghClients[h] = c
+	return c
 }
 
 // var ghGraphQlClient *githubv4.Client
@@ -47,109 +111,32 @@ func newGitHubClient() *github.Client {
 
 
 
-// GetRepoNames fetches repository names using the configured GitHub provider
+// GetRepoNames fetches repository names from the workspace's forge
 func GetRepoNames(wc config.WorkspaceConfig) ([]string, error) {
-	provider := GetProvider(wc)
-	return provider.GetRepoNames(wc.GithubOrg, wc.IsOrg, wc.ShowArchivedRepos)
+	return GetForge(wc).GetRepoNames(wc.GithubOrg, wc.IsOrg, wc.ShowArchivedRepos)
 }
 
-func GetReposByOrg(userOrOrg string, isOrg bool, showArchived bool) ([]*github.Repository, error) {
-	client := newGitHubClient()
-	ctx := context.Background()
-
-	var allRepos []*github.Repository
-	page := 1
-	perPage := 100
-
-	for {
-		var (
-			repos []*github.Repository
-			resp  *github.Response
-			err   error
-		)
-
-		if isOrg {
-			opts := &github.RepositoryListByOrgOptions{
-				ListOptions: github.ListOptions{Page: page, PerPage: perPage},
-			}
-			repos, resp, err = client.Repositories.ListByOrg(ctx, userOrOrg, opts)
-		} else {
-			opts := &github.RepositoryListByUserOptions{
-				ListOptions: github.ListOptions{Page: page, PerPage: perPage},
-			}
-			repos, resp, err = client.Repositories.ListByUser(ctx, userOrOrg, opts)
-		}
-
-		if err != nil {
-			return nil, fmt.Errorf("GitHub API error: %w", err)
-		}
-
-		allRepos = append(allRepos, repos...)
-
-		if resp.NextPage == 0 {
-			break
-		}
-		page = resp.NextPage
-	}
-
-	if showArchived {
-		return allRepos, nil
-	}
-
-	// Filter out archived repos
-	var filteredRepos []*github.Repository
-	for _, repo := range allRepos {
-		if !repo.GetArchived() {
-			filteredRepos = append(filteredRepos, repo)
-		}
-	}
-
-	return filteredRepos, nil
+// GitHubForge is the github.com or GitHub Enterprise Server Forge. Repo
+// listing goes through the workspace's GitHubProvider (GraphQL API or gh),
+// the rest through the REST client.
+type GitHubForge struct {
+	GitHubProvider
+	GitHub GitHubHost
 }
 
-func GetMyRepos(showArchived bool) ([]*github.Repository, error) {
-	client := newGitHubClient()
-	ctx := context.Background()
-
-	opts := &github.RepositoryListByAuthenticatedUserOptions{
-		ListOptions: github.ListOptions{PerPage: 100},
-	}
-
-	var allRepos []*github.Repository
-	page := 1
-
-	for {
-		opts.Page = page
-		repos, resp, err := client.Repositories.ListByAuthenticatedUser(ctx, opts)
-		if err != nil {
-			return nil, fmt.Errorf("failed to list repos: %w", err)
-		}
-		allRepos = append(allRepos, repos...)
-		if resp.NextPage == 0 {
-			break
-		}
-		page = resp.NextPage
-	}
+func (f *GitHubForge) Name() string { return "GitHub" }
 
-	if showArchived {
-		return allRepos, nil
-	}
-
-	// Filter out archived repos
-	var filteredRepos []*github.Repository
-	for _, repo := range allRepos {
-		if !repo.GetArchived() {
-			filteredRepos = append(filteredRepos, repo)
-		}
-	}
+func (f *GitHubForge) Host() string { return f.GitHub.Host }
 
-	return filteredRepos, nil
+func (f *GitHubForge) CloneURLs(owner, repo string) (string, string) {
+	return fmt.Sprintf("git@%s:%s/%s.git", sshHost(f.GitHub.Host), owner, repo),
+		fmt.Sprintf("https://%s/%s/%s.git", f.GitHub.Host, owner, repo)
 }
 
-func SearchGithubInUserOrOrg(githubOrg, search string) {
-	client := newGitHubClient()
+func SearchGithubInUserOrOrg(wc config.WorkspaceConfig, search string) {
+	client := newGitHubClient(GetGitHubHost(wc))
 
-	searchResp, githubResp, err := client.Search.Code(context.Background(), search+" org:"+githubOrg, &github.SearchOptions{
+	searchResp, githubResp, err := client.Search.Code(context.Background(), search+" org:"+wc.GithubOrg, &github.SearchOptions{
 		TextMatch: true,
 		ListOptions: github.ListOptions{
 			PerPage: 100,
@@ -196,11 +183,17 @@ func SearchGithubInUserOrOrg(githubOrg, search string) {
 	}
 }
 
+// GetWorkFlowsStatus returns the CI status of repo's branches from the
+// workspace's forge
 func GetWorkFlowsStatus(wc config.WorkspaceConfig, repo string, branches ...string) []string {
+	return GetForge(wc).PipelineStatus(wc.GithubOrg, repo, branches...)
+}
+
+// PipelineStatus reads the latest run of the deploy.yaml workflow per branch.
+func (f *GitHubForge) PipelineStatus(owner, repo string, branches ...string) []string {
 	result := []string{}
 	for _, branch := range branches {
-		client := newGitHubClient()
-		owner := wc.GithubOrg
+		client := newGitHubClient(f.GitHub)
 
 		// Get the workflow runs
 		workflowRuns, _, err := client.Actions.ListWorkflowRunsByFileName(context.Background(), owner, repo, "deploy.yaml", &github.ListWorkflowRunsOptions{
@@ -217,17 +210,13 @@ func GetWorkFlowsStatus(wc config.WorkspaceConfig, repo string, branches ...stri
 		// Get the latest run
 		latestRun := workflowRuns.WorkflowRuns[0]
 
-		emoji := "🔴"
-		if latestRun.GetConclusion() == "success" {
-			emoji = "🟢"
+		// A run still going has no conclusion yet, only a status.
+		status := latestRun.GetConclusion()
+		if status == "" {
+			status = latestRun.GetStatus()
 		}
 
-		// todo: idk if this is the string
-		if latestRun.GetConclusion() == "in_progress" {
-			emoji = "🟡"
-		}
-
-		r := branch + " " + emoji
+		r := branch + " " + emojiForStatus(status)
 		result = append(result, r)
 	}
 
@@ -235,7 +224,7 @@ func GetWorkFlowsStatus(wc config.WorkspaceConfig, repo string, branches ...stri
 }
 
 func GetOpenPullRequestsByBranch(ws config.WorkspaceConfig, project, branch string) ([]*github.PullRequest, error) {
-	client := newGitHubClient()
+	client := newGitHubClient(GetGitHubHost(ws))
 	opts := &github.PullRequestListOptions{
 		State: "open",
 		Head:  branch,
@@ -249,8 +238,13 @@ func GetOpenPullRequestsByBranch(ws config.WorkspaceConfig, project, branch stri
 	return prs, nil
 }
 
+// CreateGitHubRepo creates repoName on the workspace's forge
 func CreateGitHubRepo(ws config.WorkspaceConfig, repoName string, isPrivate bool) (string, error) {
-	client := newGitHubClient()
+	return GetForge(ws).CreateRepo(ws.GithubOrg, ws.IsOrg, repoName, isPrivate)
+}
+
+func (f *GitHubForge) CreateRepo(owner string, isOrg bool, repoName string, isPrivate bool) (string, error) {
+	client := newGitHubClient(f.GitHub)
 	ctx := context.Background()
 
 	repo := &github.Repository{
@@ -262,9 +256,9 @@ func CreateGitHubRepo(ws config.WorkspaceConfig, repoName string, isPrivate bool
 	var resp *github.Response
 	var err error
 
-	if ws.IsOrg {
+	if isOrg {
 		// Create under an organization
-		createdRepo, resp, err = client.Repositories.Create(ctx, ws.GithubOrg, repo)
+		createdRepo, resp, err = client.Repositories.Create(ctx, owner, repo)
 	} else {
 		// Create under the authenticated user
 		createdRepo, resp, err = client.Repositories.Create(ctx, "", repo)
@@ -16,26 +16,77 @@ import (
 // GitHubProvider is an interface for fetching GitHub repository information
 type GitHubProvider interface {
 	GetRepoNames(login string, isOrg bool, showArchived bool) ([]string, error)
+	// GetRepos returns every repository, archived ones included, with the
+	// metadata bootstrap filters on.
+	GetRepos(login string, isOrg bool) ([]Repo, error)
+}
+
+// Repo is a repository with its metadata.
+type Repo struct {
+	Name     string
+	Archived bool
+	Language string // primary language, "" when GitHub doesn't detect one
+	Topics   []string
+}
+
+// apiRepoNode is the GraphQL shape GetRepos reads a repository into.
+type apiRepoNode struct {
+	Name            string
+	IsArchived      bool
+	PrimaryLanguage struct {
+		Name string
+	}
+	RepositoryTopics struct {
+		Nodes []struct {
+			Topic struct {
+				Name string
+			}
+		}
+	} `graphql:"repositoryTopics(first: 20)"`
+}
+
+func (n apiRepoNode) repo() Repo {
+	r := Repo{Name: n.Name, Archived: n.IsArchived, Language: n.PrimaryLanguage.Name}
+	for _, t := range n.RepositoryTopics.Nodes {
+		r.Topics = append(r.Topics, t.Topic.Name)
+	}
+	return r
 }
 
 // APIProvider uses the GitHub GraphQL API
-type APIProvider struct{}
+type APIProvider struct {
+	GitHub GitHubHost
+}
 
-// NewAPIProvider creates a new API-based GitHub provider
-func NewAPIProvider() *APIProvider {
-	return &APIProvider{}
+// NewAPIProvider creates a new API-based GitHub provider for h
+func NewAPIProvider(h GitHubHost) *APIProvider {
+	return &APIProvider{GitHub: h}
 }
 
-// GetRepoNames fetches repository names using the GitHub GraphQL API
-func (p *APIProvider) GetRepoNames(login string, isOrg bool, showArchived bool) ([]string, error) {
-	token := os.Getenv("GITHUB_AUTH")
-	if token == "" {
-		return nil, fmt.Errorf("GITHUB_AUTH environment variable is not set")
+// client returns a GraphQL client for the provider's GitHub instance.
+func (p *APIProvider) client() (*githubv4.Client, error) {
+	if p.GitHub.Token == "" {
+		env := p.GitHub.TokenEnv
+		if env == "" {
+			env = "GITHUB_AUTH"
+		}
+		return nil, fmt.Errorf("%s environment variable is not set", env)
 	}
 
-	src := oauth2.StaticTokenSource(&oauth2.Token{AccessToken: token})
+	src := oauth2.StaticTokenSource(&oauth2.Token{AccessToken: p.GitHub.Token})
 	httpClient := oauth2.NewClient(context.Background(), src)
-	client := githubv4.NewClient(httpClient)
+	if p.GitHub.IsEnterprise() {
+		return githubv4.NewEnterpriseClient(p.GitHub.GraphQLURL(), httpClient), nil
+	}
+	return githubv4.NewClient(httpClient), nil
+}
+
+// GetRepoNames fetches repository names using the GitHub GraphQL API
+func (p *APIProvider) GetRepoNames(login string, isOrg bool, showArchived bool) ([]string, error) {
+	client, err := p.client()
+	if err != nil {
+		return nil, err
+	}
 
 	var allRepoNames []string
 	var cursor *githubv4.String
@@ -122,12 +173,101 @@ func (p *APIProvider) GetRepoNames(login string, isOrg bool, showArchived bool)
 	return allRepoNames, nil
 }
 
+// GetRepos fetches every repository with its metadata using the GitHub
+// GraphQL API
+func (p *APIProvider) GetRepos(login string, isOrg bool) ([]Repo, error) {
+	client, err := p.client()
+	if err != nil {
+		return nil, err
+	}
+
+	var repos []Repo
+	var cursor *githubv4.String
+
+	for {
+		vars := map[string]any{
+			"login":  githubv4.String(login),
+			"cursor": cursor,
+		}
+
+		var (
+			nodes    []apiRepoNode
+			pageInfo struct {
+				HasNextPage bool
+				EndCursor   githubv4.String
+			}
+		)
+
+		if isOrg {
+			var query struct {
+				Organization struct {
+					Repositories struct {
+						Nodes    []apiRepoNode
+						PageInfo struct {
+							HasNextPage bool
+							EndCursor   githubv4.String
+						}
+					} `graphql:"repositories(first: 100, after: $cursor)"`
+				} `graphql:"organization(login: $login)"`
+			}
+			if err := client.Query(context.Background(), &query, vars); err != nil {
+				return nil, fmt.Errorf("GitHub GraphQL org query failed: %w", err)
+			}
+			nodes, pageInfo = query.Organization.Repositories.Nodes, query.Organization.Repositories.PageInfo
+		} else {
+			var query struct {
+				User struct {
+					Repositories struct {
+						Nodes    []apiRepoNode
+						PageInfo struct {
+							HasNextPage bool
+							EndCursor   githubv4.String
+						}
+					} `graphql:"repositories(first: 100, after: $cursor)"`
+				} `graphql:"user(login: $login)"`
+			}
+			if err := client.Query(context.Background(), &query, vars); err != nil {
+				return nil, fmt.Errorf("GitHub GraphQL user query failed: %w", err)
+			}
+			nodes, pageInfo = query.User.Repositories.Nodes, query.User.Repositories.PageInfo
+		}
+
+		for _, node := range nodes {
+			repos = append(repos, node.repo())
+		}
+
+		if !pageInfo.HasNextPage {
+			break
+		}
+		cursor = &pageInfo.EndCursor
+	}
+
+	return repos, nil
+}
+
 // CLIProvider uses the GitHub CLI (gh)
-type CLIProvider struct{}
+type CLIProvider struct {
+	Hostname string // gh host, "" for gh's default
+}
 
-// NewCLIProvider creates a new CLI-based GitHub provider
-func NewCLIProvider() *CLIProvider {
-	return &CLIProvider{}
+// NewCLIProvider creates a new CLI-based GitHub provider. For a GitHub
+// Enterprise Server, gh must be logged in with `gh auth login --hostname`.
+func NewCLIProvider(h GitHubHost) *CLIProvider {
+	p := &CLIProvider{}
+	if h.IsEnterprise() {
+		p.Hostname = h.Host
+	}
+	return p
+}
+
+// gh builds a gh command against the provider's host. `gh repo list` has no
+// --hostname flag, so the host goes in GH_HOST.
+func (p *CLIProvider) gh(args ...string) *exec.Cmd {
+	cmd := exec.Command("gh", args...)
+	if p.Hostname != "" {
+		cmd.Env = append(os.Environ(), "GH_HOST="+p.Hostname)
+	}
+	return cmd
 }
 
 // GetRepoNames fetches repository names using the GitHub CLI
@@ -139,9 +279,9 @@ func (p *CLIProvider) GetRepoNames(login string, isOrg bool, showArchived bool)
 
 	var cmd *exec.Cmd
 	if isOrg {
-		cmd = exec.Command("gh", "repo", "list", login, "--json", "name,isArchived", "--limit", "1000")
+		cmd = p.gh("repo", "list", login, "--json", "name,isArchived", "--limit", "1000")
 	} else {
-		cmd = exec.Command("gh", "repo", "list", login, "--json", "name,isArchived", "--limit", "1000")
+		cmd = p.gh("repo", "list", login, "--json", "name,isArchived", "--limit", "1000")
 	}
 
 	output, err := cmd.CombinedOutput()
@@ -176,14 +316,55 @@ func (p *CLIProvider) GetRepoNames(login string, isOrg bool, showArchived bool)
 	return repoNames, nil
 }
 
+// GetRepos fetches every repository with its metadata using the GitHub CLI
+func (p *CLIProvider) GetRepos(login string, isOrg bool) ([]Repo, error) {
+	if _, err := exec.LookPath("gh"); err != nil {
+		return nil, fmt.Errorf("gh CLI not found: %w", err)
+	}
+
+	cmd := p.gh("repo", "list", login, "--json", "name,isArchived,primaryLanguage,repositoryTopics", "--limit", "1000")
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		return nil, fmt.Errorf("gh CLI command failed: %w\nOutput: %s", err, string(output))
+	}
+
+	var ghRepos []struct {
+		Name            string `json:"name"`
+		IsArchived      bool   `json:"isArchived"`
+		PrimaryLanguage struct {
+			Name string `json:"name"`
+		} `json:"primaryLanguage"`
+		RepositoryTopics []struct {
+			Name string `json:"name"`
+		} `json:"repositoryTopics"`
+	}
+	if err := json.Unmarshal(output, &ghRepos); err != nil {
+		return nil, fmt.Errorf("failed to parse gh CLI output: %w", err)
+	}
+
+	repos := make([]Repo, 0, len(ghRepos))
+	for _, r := range ghRepos {
+		repo := Repo{Name: r.Name, Archived: r.IsArchived, Language: r.PrimaryLanguage.Name}
+		if parts := strings.Split(r.Name, "/"); len(parts) > 1 {
+			repo.Name = parts[1]
+		}
+		for _, t := range r.RepositoryTopics {
+			repo.Topics = append(repo.Topics, t.Name)
+		}
+		repos = append(repos, repo)
+	}
+
+	return repos, nil
+}
+
 // GetProvider returns the appropriate GitHub provider based on the workspace config
 func GetProvider(wc config.WorkspaceConfig) GitHubProvider {
 	switch wc.GithubBackend {
 	case config.GithubBackendCLI:
-		return NewCLIProvider()
+		return NewCLIProvider(GetGitHubHost(wc))
 	case config.GithubBackendAPI:
 		fallthrough
 	default:
-		return NewAPIProvider()
+		return NewAPIProvider(GetGitHubHost(wc))
 	}
 }
@@ -0,0 +1,62 @@
+package workspacer
+
+import (
+	"testing"
+
+	"github.com/JamesTiberiusKirk/workspacer/config"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetGitHubHost(t *testing.T) {
+	t.Setenv("GITHUB_AUTH", "public")
+	t.Setenv("GHE_TOKEN", "enterprise")
+
+	tests := []struct {
+		name    string
+		wc      config.WorkspaceConfig
+		want    GitHubHost
+		graphQL string
+		ssh     string
+	}{
+		{
+			name:    "Defaults_to_github_com",
+			wc:      config.WorkspaceConfig{},
+			want:    GitHubHost{Host: "github.com", Token: "public", TokenEnv: "GITHUB_AUTH"},
+			graphQL: "https://api.github.com/graphql",
+			ssh:     "git@github.com:acme/api.git",
+		},
+		{
+			name:    "Enterprise_host",
+			wc:      config.WorkspaceConfig{GithubHost: "github.corp.com", ForgeTokenEnv: "GHE_TOKEN"},
+			want:    GitHubHost{Host: "github.corp.com", APIURL: "https://github.corp.com/api/v3/", Token: "enterprise", TokenEnv: "GHE_TOKEN"},
+			graphQL: "https://github.corp.com/api/graphql",
+			ssh:     "git@github.corp.com:acme/api.git",
+		},
+		{
+			name:    "Host_from_api_url",
+			wc:      config.WorkspaceConfig{GithubAPIURL: "https://api.github.corp.com/"},
+			want:    GitHubHost{Host: "github.corp.com", APIURL: "https://api.github.corp.com/", Token: "public", TokenEnv: "GITHUB_AUTH"},
+			graphQL: "https://api.github.corp.com/graphql",
+			ssh:     "git@github.corp.com:acme/api.git",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := GetGitHubHost(tt.wc)
+			assert.Equal(t, tt.want, h)
+			assert.Equal(t, tt.graphQL, h.GraphQLURL())
+
+			ssh, _ := (&GitHubForge{GitHub: h}).CloneURLs("acme", "api")
+			assert.Equal(t, tt.ssh, ssh)
+		})
+	}
+}
+
+func TestAPIProviderMissingToken(t *testing.T) {
+	t.Setenv("GHE_TOKEN", "")
+	h := GetGitHubHost(config.WorkspaceConfig{GithubHost: "github.corp.com", ForgeTokenEnv: "GHE_TOKEN"})
+
+	_, err := NewAPIProvider(h).GetRepoNames("acme", true, false)
+	assert.EqualError(t, err, "GHE_TOKEN environment variable is not set")
+}
@@ -0,0 +1,152 @@
+package workspacer
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+const defaultGitLabURL = "https://gitlab.com"
+
+// GitLabForge is the Forge for gitlab.com or a self-managed GitLab, talking
+// to its REST API (v4). Owners are group paths (subgroups as "group/sub") or
+// usernames.
+type GitLabForge struct {
+	BaseURL string // e.g. https://gitlab.example.com, without /api/v4
+	Token   string // personal/group access token; "" for public data only
+	Client  *http.Client
+}
+
+// NewGitLabForge returns a GitLab forge for baseURL ("" = gitlab.com).
+func NewGitLabForge(baseURL, token string) *GitLabForge {
+	if baseURL == "" {
+		baseURL = defaultGitLabURL
+	}
+	return &GitLabForge{
+		BaseURL: strings.TrimSuffix(baseURL, "/"),
+		Token:   token,
+		Client:  http.DefaultClient,
+	}
+}
+
+// gitlabProject is the part of the projects API response workspacer reads.
+type gitlabProject struct {
+	Path              string   `json:"path"`
+	PathWithNamespace string   `json:"path_with_namespace"`
+	Archived          bool     `json:"archived"`
+	Topics            []string `json:"topics"`
+}
+
+func (f *GitLabForge) GetRepoNames(login string, isOrg bool, showArchived bool) ([]string, error) {
+	repos, err := f.GetRepos(login, isOrg)
+	if err != nil {
+		return nil, err
+	}
+
+	var names []string
+	for _, r := range repos {
+		if !showArchived && r.Archived {
+			continue
+		}
+		names = append(names, r.Name)
+	}
+	return names, nil
+}
+
+// GetRepos lists the group's (isOrg) or user's projects. Groups include their
+// subgroups' projects, named by their path below the group (e.g. infra/tf) so
+// they clone from the right URL. The list API doesn't return languages, so
+// Repo.Language is always empty.
+func (f *GitLabForge) GetRepos(login string, isOrg bool) ([]Repo, error) {
+	path := "/users/" + url.PathEscape(login) + "/projects"
+	query := url.Values{"per_page": {"100"}}
+	if isOrg {
+		path = "/groups/" + url.PathEscape(login) + "/projects"
+		query.Set("include_subgroups", "true")
+	}
+
+	var repos []Repo
+	page := "1"
+	for page != "" {
+		var projects []gitlabProject
+		query.Set("page", page)
+		resp, err := f.do(http.MethodGet, path, query, nil, &projects)
+		if err != nil {
+			return nil, err
+		}
+		for _, p := range projects {
+			name := p.Path
+			if rel, ok := strings.CutPrefix(p.PathWithNamespace, login+"/"); ok {
+				name = rel
+			}
+			repos = append(repos, Repo{Name: name, Archived: p.Archived, Topics: p.Topics})
+		}
+		page = resp.Header.Get("X-Next-Page")
+	}
+	return repos, nil
+}
+
+func (f *GitLabForge) Name() string { return "GitLab" }
+
+func (f *GitLabForge) Host() string { return hostOf(f.BaseURL) }
+
+func (f *GitLabForge) CloneURLs(owner, repo string) (string, string) {
+	return fmt.Sprintf("git@%s:%s/%s.git", sshHost(f.Host()), owner, repo),
+		fmt.Sprintf("%s/%s/%s.git", f.BaseURL, owner, repo)
+}
+
+// CreateRepo creates a project in the owner group, or in the token's user
+// namespace when !isOrg.
+func (f *GitLabForge) CreateRepo(owner string, isOrg bool, name string, private bool) (string, error) {
+	body := map[string]any{
+		"name":       name,
+		"path":       name,
+		"visibility": "public",
+	}
+	if private {
+		body["visibility"] = "private"
+	}
+
+	if isOrg {
+		var group struct {
+			ID int `json:"id"`
+		}
+		if _, err := f.do(http.MethodGet, "/groups/"+url.PathEscape(owner), nil, nil, &group); err != nil {
+			return "", fmt.Errorf("failed to look up group %s: %w", owner, err)
+		}
+		body["namespace_id"] = group.ID
+	}
+
+	var created gitlabProject
+	if _, err := f.do(http.MethodPost, "/projects", nil, body, &created); err != nil {
+		return "", fmt.Errorf("failed to create repo: %w", err)
+	}
+	return created.Path, nil
+}
+
+// PipelineStatus reads the latest pipeline of each branch.
+func (f *GitLabForge) PipelineStatus(owner, repo string, branches ...string) []string {
+	result := []string{}
+	path := "/projects/" + url.PathEscape(owner+"/"+repo) + "/pipelines"
+	for _, branch := range branches {
+		var pipelines []struct {
+			Status string `json:"status"`
+		}
+		_, err := f.do(http.MethodGet, path, url.Values{"ref": {branch}, "per_page": {"1"}}, nil, &pipelines)
+		if err != nil || len(pipelines) == 0 {
+			continue
+		}
+		result = append(result, branch+" "+emojiForStatus(pipelines[0].Status))
+	}
+	return result
+}
+
+// do sends a request to the API and decodes a JSON response into out.
+func (f *GitLabForge) do(method, path string, query url.Values, body, out any) (*http.Response, error) {
+	header := http.Header{}
+	if f.Token != "" {
+		header.Set("PRIVATE-TOKEN", f.Token)
+	}
+	return forgeRequest(f.Client, "GitLab", method, f.BaseURL+"/api/v4", path, query, header, body, out)
+}
@@ -0,0 +1,115 @@
+package workspacer
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeGitLab stands in for the GitLab REST API: two pages of projects in the
+// acme/platform group and its infra subgroup, project creation and pipelines.
+func fakeGitLab(t *testing.T) (*httptest.Server, *map[string]any) {
+	t.Helper()
+	created := map[string]any{}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /api/v4/groups/{group}/projects", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/api/v4/groups/acme%2Fplatform/projects", r.URL.EscapedPath())
+		assert.Equal(t, "true", r.URL.Query().Get("include_subgroups"))
+		if r.URL.Query().Get("page") == "1" {
+			w.Header().Set("X-Next-Page", "2")
+			json.NewEncoder(w).Encode([]gitlabProject{{Path: "api", Topics: []string{"service"}}, {Path: "old", Archived: true}})
+			return
+		}
+		json.NewEncoder(w).Encode([]gitlabProject{
+			{Path: "web", PathWithNamespace: "acme/platform/web"},
+			{Path: "tf", PathWithNamespace: "acme/platform/infra/tf"},
+		})
+	})
+	mux.HandleFunc("GET /api/v4/groups/{group}", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"id": 42}`))
+	})
+	mux.HandleFunc("POST /api/v4/projects", func(w http.ResponseWriter, r *http.Request) {
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&created))
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte(`{"path": "new-svc"}`))
+	})
+	mux.HandleFunc("GET /api/v4/projects/{project}/pipelines", func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Query().Get("ref") {
+		case "main":
+			w.Write([]byte(`[{"status": "success"}]`))
+		case "staging":
+			w.Write([]byte(`[{"status": "running"}]`))
+		default:
+			w.Write([]byte(`[]`))
+		}
+	})
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("PRIVATE-TOKEN") != "secret" {
+			http.Error(w, `{"message": "401 Unauthorized"}`, http.StatusUnauthorized)
+			return
+		}
+		mux.ServeHTTP(w, r)
+	}))
+	t.Cleanup(srv.Close)
+	return srv, &created
+}
+
+func TestGitLabForge(t *testing.T) {
+	srv, created := fakeGitLab(t)
+	f := NewGitLabForge(srv.URL+"/", "secret")
+
+	t.Run("Lists_group_projects_across_pages", func(t *testing.T) {
+		names, err := f.GetRepoNames("acme/platform", true, false)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"api", "web", "infra/tf"}, names)
+
+		repos, err := f.GetRepos("acme/platform", true)
+		require.NoError(t, err)
+		assert.Equal(t, []Repo{
+			{Name: "api", Topics: []string{"service"}},
+			{Name: "old", Archived: true},
+			{Name: "web"},
+			{Name: "infra/tf"},
+		}, repos, "subgroup projects are named by their path below the group")
+	})
+
+	t.Run("Clone_urls", func(t *testing.T) {
+		ssh, https := f.CloneURLs("acme", "api")
+		assert.Equal(t, "git@127.0.0.1:acme/api.git", ssh, "the web port isn't used for SSH")
+		assert.Equal(t, srv.URL+"/acme/api.git", https)
+
+		ssh, _ = f.CloneURLs("acme/platform", "infra/tf")
+		assert.Equal(t, "git@127.0.0.1:acme/platform/infra/tf.git", ssh)
+
+		ssh, https = NewGitLabForge("https://gitlab.example.com:8443", "").CloneURLs("acme", "api")
+		assert.Equal(t, "git@gitlab.example.com:acme/api.git", ssh)
+		assert.Equal(t, "https://gitlab.example.com:8443/acme/api.git", https)
+	})
+
+	t.Run("Creates_project_in_group", func(t *testing.T) {
+		name, err := f.CreateRepo("acme", true, "new-svc", true)
+		require.NoError(t, err)
+		assert.Equal(t, "new-svc", name)
+		assert.Equal(t, map[string]any{
+			"name":         "new-svc",
+			"path":         "new-svc",
+			"visibility":   "private",
+			"namespace_id": float64(42),
+		}, *created)
+	})
+
+	t.Run("Pipeline_status", func(t *testing.T) {
+		assert.Equal(t, []string{"main 🟢", "staging 🟡"}, f.PipelineStatus("acme", "api", "main", "staging", "feature"))
+	})
+
+	t.Run("Reports_api_errors", func(t *testing.T) {
+		_, err := NewGitLabForge(srv.URL, "wrong").GetRepos("acme", true)
+		assert.ErrorContains(t, err, "401")
+	})
+}
@@ -0,0 +1,705 @@
+package workspacer
+
+import (
+	"bufio"
+	"bytes"
+	"container/heap"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/JamesTiberiusKirk/workspacer/util"
+	"github.com/go-git/go-billy/v5/osfs"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/cache"
+	"github.com/go-git/go-git/v5/plumbing/filemode"
+	gitconfig "github.com/go-git/go-git/v5/plumbing/format/config"
+	"github.com/go-git/go-git/v5/plumbing/format/gitignore"
+	"github.com/go-git/go-git/v5/plumbing/format/index"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/go-git/go-git/v5/storage/filesystem"
+)
+
+// The picker reads the git info of every project each time it opens, so it
+// reads .git directly instead of running git: refs and config are parsed by
+// hand, the index, ignore files and objects through go-git.
+
+// gitStatus is everything the picker and sync read about a repo.
+type gitStatus struct {
+	branch  string // "HEAD" when detached, like rev-parse --abbrev-ref
+	changes int    // one per `git status --porcelain` line
+	gitState
+}
+
+// readGitStatus reads the repo at path without running git. When the index
+// is in a format we can't read (split or sparse index), the tree can't be
+// walked, or git converts files on checkout (autocrlf, eol, filters like
+// git-lfs), the change count falls back to git status.
+func readGitStatus(path string) (gitStatus, error) {
+	r, err := openGitRepo(path)
+	if err != nil {
+		return gitStatus{}, err
+	}
+
+	var s gitStatus
+	head, err := r.head()
+	if err != nil {
+		return gitStatus{}, err
+	}
+	s.branch = head.branch
+	s.detached = head.branch == "HEAD"
+	s.rebasing = r.rebasing()
+	s.stashes = r.stashCount()
+
+	if !head.hash.IsZero() {
+		if c, err := r.commit(head.hash); err == nil {
+			s.lastCommit = c.Committer.When
+		}
+		if up, err := r.resolve(r.upstream(head.branch)); err == nil {
+			s.ahead, s.behind, err = r.aheadBehind(head.hash, up)
+			s.hasUpstream = err == nil
+		}
+	}
+
+	s.changes, err = r.changes(head.hash)
+	if err != nil {
+		out, err := util.ExecCmd(path, "git", "status", "--porcelain")
+		if err != nil {
+			return s, fmt.Errorf("status: %s", gitError(err))
+		}
+		if out = strings.TrimSpace(out); out != "" {
+			s.changes = len(strings.Split(out, "\n"))
+		}
+	}
+	return s, nil
+}
+
+// gitRepo is a checkout: its work tree, its own git dir (HEAD, index) and the
+// dir shared with the other worktrees (refs, objects, config), which is the
+// same one unless the checkout is a linked worktree.
+type gitRepo struct {
+	path      string
+	gitDir    string
+	commonDir string
+
+	objects *filesystem.Storage
+	config  *gitconfig.Config
+}
+
+func openGitRepo(path string) (*gitRepo, error) {
+	r := &gitRepo{path: path, gitDir: filepath.Join(path, ".git")}
+
+	fi, err := os.Stat(r.gitDir)
+	if err != nil {
+		return nil, fmt.Errorf("not a git repo: %w", err)
+	}
+	if !fi.IsDir() {
+		// Worktrees and submodules have a .git file pointing at their git dir.
+		b, err := os.ReadFile(r.gitDir)
+		if err != nil {
+			return nil, err
+		}
+		dir, ok := strings.CutPrefix(strings.TrimSpace(string(b)), "gitdir: ")
+		if !ok {
+			return nil, fmt.Errorf("unexpected .git file in %s", path)
+		}
+		r.gitDir = absTo(path, dir)
+	}
+
+	r.commonDir = r.gitDir
+	if b, err := os.ReadFile(filepath.Join(r.gitDir, "commondir")); err == nil {
+		r.commonDir = absTo(r.gitDir, strings.TrimSpace(string(b)))
+	}
+
+	r.config = gitconfig.New()
+	if b, err := os.ReadFile(filepath.Join(r.commonDir, "config")); err == nil {
+		_ = gitconfig.NewDecoder(bytes.NewReader(b)).Decode(r.config)
+	}
+	return r, nil
+}
+
+func absTo(base, path string) string {
+	if filepath.IsAbs(path) {
+		return path
+	}
+	return filepath.Join(base, path)
+}
+
+type gitHead struct {
+	branch string
+	hash   plumbing.Hash // zero on an unborn branch
+}
+
+// head reads .git/HEAD: a symbolic ref to the checked out branch, or a bare
+// hash when detached.
+func (r *gitRepo) head() (gitHead, error) {
+	b, err := os.ReadFile(filepath.Join(r.gitDir, "HEAD"))
+	if err != nil {
+		return gitHead{}, err
+	}
+	line := strings.TrimSpace(string(b))
+
+	ref, ok := strings.CutPrefix(line, "ref: ")
+	if !ok {
+		return gitHead{branch: "HEAD", hash: plumbing.NewHash(line)}, nil
+	}
+	h := gitHead{branch: strings.TrimPrefix(ref, "refs/heads/")}
+	if hash, err := r.resolve(ref); err == nil {
+		h.hash = hash
+	}
+	return h, nil
+}
+
+// resolve finds the commit a full ref name points at, loose refs first, then
+// packed-refs, following symbolic refs.
+func (r *gitRepo) resolve(ref string) (plumbing.Hash, error) {
+	for range 5 {
+		if ref == "" {
+			break
+		}
+		b, err := os.ReadFile(filepath.Join(r.commonDir, filepath.FromSlash(ref)))
+		if err != nil {
+			return r.packedRef(ref)
+		}
+		line := strings.TrimSpace(string(b))
+		target, ok := strings.CutPrefix(line, "ref: ")
+		if !ok {
+			return plumbing.NewHash(line), nil
+		}
+		ref = target
+	}
+	return plumbing.ZeroHash, plumbing.ErrReferenceNotFound
+}
+
+func (r *gitRepo) packedRef(ref string) (plumbing.Hash, error) {
+	f, err := os.Open(filepath.Join(r.commonDir, "packed-refs"))
+	if err != nil {
+		return plumbing.ZeroHash, plumbing.ErrReferenceNotFound
+	}
+	defer f.Close()
+
+	s := bufio.NewScanner(f)
+	for s.Scan() {
+		hash, name, ok := strings.Cut(s.Text(), " ")
+		if ok && name == ref {
+			return plumbing.NewHash(hash), nil
+		}
+	}
+	return plumbing.ZeroHash, plumbing.ErrReferenceNotFound
+}
+
+// upstream is the remote-tracking ref branch merges from, per
+// branch.<name>.remote and .merge, "" when there is none.
+func (r *gitRepo) upstream(branch string) string {
+	if branch == "HEAD" {
+		return ""
+	}
+	opts := r.config.Section("branch").Subsection(branch).Options
+	remote, merge := opts.Get("remote"), opts.Get("merge")
+	if remote == "" || merge == "" {
+		return ""
+	}
+	if remote == "." {
+		return merge
+	}
+	return "refs/remotes/" + remote + "/" + strings.TrimPrefix(merge, "refs/heads/")
+}
+
+// stashCount is the number of entries in the stash reflog.
+func (r *gitRepo) stashCount() int {
+	b, err := os.ReadFile(filepath.Join(r.commonDir, "logs", "refs", "stash"))
+	if err != nil {
+		return 0
+	}
+	return bytes.Count(b, []byte("\n"))
+}
+
+func (r *gitRepo) rebasing() bool {
+	for _, dir := range []string{"rebase-merge", "rebase-apply"} {
+		if _, err := os.Stat(filepath.Join(r.gitDir, dir)); err == nil {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *gitRepo) commit(h plumbing.Hash) (*object.Commit, error) {
+	if r.objects == nil {
+		r.objects = filesystem.NewStorage(osfs.New(r.commonDir), cache.NewObjectLRUDefault())
+	}
+	return object.GetCommit(r.objects, h)
+}
+
+// Flags of the ahead/behind walk.
+const (
+	reachLeft  = 1 << iota // reachable from HEAD
+	reachRight             // reachable from the upstream
+	reachBoth  = reachLeft | reachRight
+)
+
+// aheadBehind counts the commits reachable from only left or only right, like
+// rev-list --left-right --count left...right. Commits are walked newest first
+// and the walk stops once everything left to visit is reachable from both, so
+// only the history since the merge base is read.
+func (r *gitRepo) aheadBehind(left, right plumbing.Hash) (ahead, behind int, err error) {
+	if left == right {
+		return 0, 0, nil
+	}
+
+	flags := map[plumbing.Hash]int{left: reachLeft, right: reachRight}
+	queue := &commitQueue{}
+	for _, h := range []plumbing.Hash{left, right} {
+		c, err := r.commit(h)
+		if err != nil {
+			return 0, 0, err
+		}
+		heap.Push(queue, c)
+	}
+
+	for queue.Len() > 0 && !queue.allBoth(flags) {
+		c := heap.Pop(queue).(*object.Commit)
+		f := flags[c.Hash]
+		for _, p := range c.ParentHashes {
+			if flags[p]|f == flags[p] {
+				continue
+			}
+			flags[p] |= f
+			pc, err := r.commit(p)
+			if err != nil {
+				// Shallow clones end in commits we don't have.
+				if errors.Is(err, plumbing.ErrObjectNotFound) {
+					continue
+				}
+				return 0, 0, err
+			}
+			heap.Push(queue, pc)
+		}
+	}
+
+	for _, f := range flags {
+		switch f {
+		case reachLeft:
+			ahead++
+		case reachRight:
+			behind++
+		}
+	}
+	return ahead, behind, nil
+}
+
+// commitQueue pops the most recently committed commit first.
+type commitQueue []*object.Commit
+
+func (q commitQueue) Len() int { return len(q) }
+func (q commitQueue) Less(i, j int) bool {
+	return q[i].Committer.When.After(q[j].Committer.When)
+}
+func (q commitQueue) Swap(i, j int) { q[i], q[j] = q[j], q[i] }
+func (q *commitQueue) Push(x any)   { *q = append(*q, x.(*object.Commit)) }
+func (q *commitQueue) Pop() any {
+	old := *q
+	c := old[len(old)-1]
+	*q = old[:len(old)-1]
+	return c
+}
+
+func (q commitQueue) allBoth(flags map[plumbing.Hash]int) bool {
+	for _, c := range q {
+		if flags[c.Hash] != reachBoth {
+			return false
+		}
+	}
+	return true
+}
+
+// changes counts what `git status --porcelain` would list: paths whose index
+// entry differs from HEAD or whose file differs from the index, plus untracked
+// files, an untracked directory counting once. A staged rename counts once
+// like git's, but only when the content is unchanged: git also pairs up a
+// delete and add of similar content, which count twice here.
+func (r *gitRepo) changes(head plumbing.Hash) (int, error) {
+	idx, err := r.index()
+	if err != nil {
+		return 0, err
+	}
+
+	if r.convertsContent(idx) {
+		return 0, errors.New("files are converted on checkout")
+	}
+
+	changed := map[string]bool{}
+	if err := r.stagedChanges(idx, head, changed); err != nil {
+		return 0, err
+	}
+	r.unstagedChanges(idx, changed)
+
+	untracked := 0
+	if !strings.EqualFold(r.config.Section("status").Options.Get("showUntrackedFiles"), "no") {
+		untracked = r.untracked(idx)
+	}
+	return len(changed) + untracked, nil
+}
+
+func (r *gitRepo) index() (*index.Index, error) {
+	idx := &index.Index{Version: 2}
+	f, err := os.Open(filepath.Join(r.gitDir, "index"))
+	if os.IsNotExist(err) {
+		return idx, nil
+	} else if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	if err := index.NewDecoder(bufio.NewReader(f)).Decode(idx); err != nil {
+		return nil, fmt.Errorf("reading index: %w", err)
+	}
+	for _, e := range idx.Entries {
+		if e.SkipWorktree {
+			return nil, errors.New("sparse checkouts aren't supported")
+		}
+	}
+	return idx, nil
+}
+
+// contentAttributes are the gitattributes that make git convert a file
+// between the work tree and the index.
+var contentAttributes = []string{"text", "eol", "crlf", "filter", "ident", "working-tree-encoding"}
+
+// convertsContent reports whether git may convert files between the work tree
+// and the index: core.autocrlf is on, or a gitattributes file sets text, eol,
+// a filter (git-lfs) and the like. A file's bytes on disk then don't hash to
+// its index entry, so the change count is left to git status.
+func (r *gitRepo) convertsContent(idx *index.Index) bool {
+	global := globalGitConfig()
+	autocrlf := global.Section("core").Options.Get("autocrlf")
+	if v := r.config.Section("core").Options.Get("autocrlf"); v != "" {
+		autocrlf = v
+	}
+	if autocrlf != "" && !strings.EqualFold(autocrlf, "false") {
+		return true
+	}
+
+	attributesFile := global.Section("core").Options.Get("attributesFile")
+	if v := r.config.Section("core").Options.Get("attributesFile"); v != "" {
+		attributesFile = v
+	}
+	if attributesFile == "" {
+		attributesFile = xdgGitFile("attributes")
+	} else if rest, ok := strings.CutPrefix(attributesFile, "~/"); ok {
+		home, _ := os.UserHomeDir()
+		attributesFile = filepath.Join(home, rest)
+	}
+
+	files := []string{attributesFile, filepath.Join(r.commonDir, "info", "attributes"), filepath.Join(r.path, ".gitattributes")}
+	for _, e := range idx.Entries {
+		if strings.HasSuffix(e.Name, "/.gitattributes") {
+			files = append(files, filepath.Join(r.path, filepath.FromSlash(e.Name)))
+		}
+	}
+	for _, f := range files {
+		if setsContentAttribute(f) {
+			return true
+		}
+	}
+	return false
+}
+
+// setsContentAttribute reports whether the gitattributes file at path sets
+// any of contentAttributes for some pattern.
+func setsContentAttribute(path string) bool {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return false
+	}
+	for _, line := range strings.Split(string(b), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 2 || strings.HasPrefix(fields[0], "#") || strings.HasPrefix(fields[0], "[attr]") {
+			continue
+		}
+		for _, attr := range fields[1:] {
+			if strings.HasPrefix(attr, "-") || strings.HasPrefix(attr, "!") {
+				continue
+			}
+			name, _, _ := strings.Cut(attr, "=")
+			if slices.Contains(contentAttributes, name) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// stagedChanges adds the paths whose index entry differs from HEAD, leaving
+// out the old path of a file staged under a new name as is. When the index's
+// cached tree is valid and matches HEAD's, nothing is staged and HEAD isn't
+// read at all.
+func (r *gitRepo) stagedChanges(idx *index.Index, head plumbing.Hash, changed map[string]bool) error {
+	headFiles := map[string]plumbing.Hash{}
+	if !head.IsZero() {
+		c, err := r.commit(head)
+		if err != nil {
+			return err
+		}
+		if idx.Cache != nil && len(idx.Cache.Entries) > 0 {
+			root := idx.Cache.Entries[0]
+			if root.Path == "" && root.Entries >= 0 && root.Hash == c.TreeHash {
+				return nil
+			}
+		}
+
+		tree, err := c.Tree()
+		if err != nil {
+			return err
+		}
+		walker := object.NewTreeWalker(tree, true, nil)
+		defer walker.Close()
+		for {
+			name, e, err := walker.Next()
+			if err == io.EOF {
+				break
+			} else if err != nil {
+				return err
+			}
+			if e.Mode != filemode.Dir {
+				headFiles[name] = e.Hash
+			}
+		}
+	}
+
+	added := map[plumbing.Hash]int{}
+	for _, e := range idx.Entries {
+		h, ok := headFiles[e.Name]
+		if !ok || h != e.Hash || e.Stage != 0 {
+			changed[e.Name] = true
+		}
+		if !ok && e.Stage == 0 {
+			added[e.Hash]++
+		}
+		delete(headFiles, e.Name)
+	}
+	// What's left of HEAD is deleted, unless its content was added elsewhere.
+	for name, h := range headFiles {
+		if added[h] > 0 {
+			added[h]--
+			continue
+		}
+		changed[name] = true
+	}
+	return nil
+}
+
+// unstagedChanges adds the tracked paths whose file differs from the index.
+// Files with the size and mtime the index recorded are taken as unchanged,
+// unless they were written in the same second as the index; the rest are
+// hashed.
+func (r *gitRepo) unstagedChanges(idx *index.Index, changed map[string]bool) {
+	var indexTime int64
+	if fi, err := os.Stat(filepath.Join(r.gitDir, "index")); err == nil {
+		indexTime = fi.ModTime().Unix()
+	}
+
+	for _, e := range idx.Entries {
+		if changed[e.Name] {
+			continue
+		}
+		path := filepath.Join(r.path, filepath.FromSlash(e.Name))
+		fi, err := os.Lstat(path)
+		if err != nil || e.IntentToAdd {
+			changed[e.Name] = true
+			continue
+		}
+
+		if e.Mode == filemode.Submodule {
+			if sub, err := openGitRepo(path); err == nil {
+				if h, err := sub.head(); err == nil && h.hash != e.Hash {
+					changed[e.Name] = true
+				}
+			}
+			continue
+		}
+
+		mode, err := filemode.NewFromOSFileMode(fi.Mode())
+		if err != nil || mode != e.Mode {
+			changed[e.Name] = true
+			continue
+		}
+		racy := fi.ModTime().Unix() >= indexTime
+		if uint32(fi.Size()) == e.Size && fi.ModTime().Equal(e.ModifiedAt) && !racy {
+			continue
+		}
+		if h, err := blobHash(path, mode); err != nil || h != e.Hash {
+			changed[e.Name] = true
+		}
+	}
+}
+
+func blobHash(path string, mode filemode.FileMode) (plumbing.Hash, error) {
+	var content []byte
+	if mode == filemode.Symlink {
+		target, err := os.Readlink(path)
+		if err != nil {
+			return plumbing.ZeroHash, err
+		}
+		content = []byte(filepath.ToSlash(target))
+	} else {
+		b, err := os.ReadFile(path)
+		if err != nil {
+			return plumbing.ZeroHash, err
+		}
+		content = b
+	}
+	return plumbing.ComputeHash(plumbing.BlobObject, content), nil
+}
+
+// untracked counts the untracked files git status would list. Ignored
+// directories are never read, and an untracked directory counts once, as soon
+// as it's known to hold something that isn't ignored.
+func (r *gitRepo) untracked(idx *index.Index) int {
+	tracked := map[string]bool{}
+	trackedDirs := map[string]bool{}
+	for _, e := range idx.Entries {
+		tracked[e.Name] = true
+		for dir := filepath.ToSlash(filepath.Dir(e.Name)); dir != "."; dir = filepath.ToSlash(filepath.Dir(dir)) {
+			if trackedDirs[dir] {
+				break
+			}
+			trackedDirs[dir] = true
+		}
+	}
+
+	patterns := append([]gitignore.Pattern{}, globalIgnores()...)
+	patterns = append(patterns, readIgnoreFile(filepath.Join(r.commonDir, "info", "exclude"), nil)...)
+
+	var walk func(dir []string, patterns []gitignore.Pattern) int
+	walk = func(dir []string, patterns []gitignore.Pattern) int {
+		patterns = append(patterns, readIgnoreFile(filepath.Join(r.path, filepath.Join(dir...), ".gitignore"), dir)...)
+		m := gitignore.NewMatcher(patterns)
+		entries, _ := os.ReadDir(filepath.Join(r.path, filepath.Join(dir...)))
+
+		n := 0
+		for _, e := range entries {
+			if len(dir) == 0 && e.Name() == ".git" {
+				continue
+			}
+			parts := append(append([]string{}, dir...), e.Name())
+			name := strings.Join(parts, "/")
+			switch {
+			case tracked[name]:
+			case trackedDirs[name]:
+				n += walk(parts, patterns)
+			case m.Match(parts, e.IsDir()):
+			case !e.IsDir():
+				n++
+			case r.hasUntracked(parts, patterns):
+				n++
+			}
+		}
+		return n
+	}
+	return walk(nil, patterns)
+}
+
+// hasUntracked reports whether the untracked directory dir holds a file that
+// isn't ignored, or is a repo of its own.
+func (r *gitRepo) hasUntracked(dir []string, patterns []gitignore.Pattern) bool {
+	path := filepath.Join(r.path, filepath.Join(dir...))
+	if _, err := os.Stat(filepath.Join(path, ".git")); err == nil {
+		return true
+	}
+	patterns = append(patterns, readIgnoreFile(filepath.Join(path, ".gitignore"), dir)...)
+	m := gitignore.NewMatcher(patterns)
+
+	entries, _ := os.ReadDir(path)
+	for _, e := range entries {
+		parts := append(append([]string{}, dir...), e.Name())
+		if m.Match(parts, e.IsDir()) {
+			continue
+		}
+		if !e.IsDir() || r.hasUntracked(parts, patterns) {
+			return true
+		}
+	}
+	return false
+}
+
+func readIgnoreFile(path string, domain []string) []gitignore.Pattern {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil
+	}
+	var ps []gitignore.Pattern
+	for _, line := range strings.Split(string(b), "\n") {
+		line = strings.TrimRight(line, "\r")
+		if strings.TrimSpace(line) == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		ps = append(ps, gitignore.ParsePattern(line, domain))
+	}
+	return ps
+}
+
+var (
+	globalIgnoresOnce sync.Once
+	globalIgnoreList  []gitignore.Pattern
+)
+
+// globalIgnores are the core.excludesfile patterns of the system and user
+// git config, or ~/.config/git/ignore when the user sets none. They're read
+// once per run.
+func globalIgnores() []gitignore.Pattern {
+	globalIgnoresOnce.Do(func() {
+		root := osfs.New("/")
+		system, _ := gitignore.LoadSystemPatterns(root)
+		user, _ := gitignore.LoadGlobalPatterns(root)
+		if user == nil {
+			user = readIgnoreFile(xdgGitIgnore(), nil)
+		}
+		globalIgnoreList = append(system, user...)
+	})
+	return globalIgnoreList
+}
+
+func xdgGitIgnore() string {
+	return xdgGitFile("ignore")
+}
+
+// xdgGitFile is the path of git's per-user file name, e.g. ignore or
+// attributes.
+func xdgGitFile(name string) string {
+	if dir := os.Getenv("XDG_CONFIG_HOME"); dir != "" {
+		return filepath.Join(dir, "git", name)
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(home, ".config", "git", name)
+}
+
+var (
+	globalGitConfigOnce sync.Once
+	globalGitConfigData *gitconfig.Config
+)
+
+// globalGitConfig is the system and user git config merged, the user's
+// winning. It's read once per run.
+func globalGitConfig() *gitconfig.Config {
+	globalGitConfigOnce.Do(func() {
+		globalGitConfigData = gitconfig.New()
+		files := []string{"/etc/gitconfig", xdgGitFile("config")}
+		if home, err := os.UserHomeDir(); err == nil {
+			files = append(files, filepath.Join(home, ".gitconfig"))
+		}
+		for _, f := range files {
+			if b, err := os.ReadFile(f); err == nil {
+				_ = gitconfig.NewDecoder(bytes.NewReader(b)).Decode(globalGitConfigData)
+			}
+		}
+	})
+	return globalGitConfigData
+}
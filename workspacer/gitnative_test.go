@@ -0,0 +1,227 @@
+package workspacer
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/JamesTiberiusKirk/workspacer/config"
+	"github.com/JamesTiberiusKirk/workspacer/util"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeFile(t testing.TB, path, content string) {
+	t.Helper()
+	require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+	require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+}
+
+// porcelainCount is what GetUncommittedChangesCount used to return.
+func porcelainCount(t *testing.T, dir string) int {
+	out := gitRun(t, dir, "status", "--porcelain")
+	if out == "" {
+		return 0
+	}
+	return len(strings.Split(out, "\n"))
+}
+
+func TestReadGitStatusMatchesGit(t *testing.T) {
+	// Each repo starts with a commit tracking a.txt, src/b.txt and .gitignore
+	// (ignoring build/ and *.log).
+	tests := []struct {
+		name  string
+		setup func(t *testing.T, dir string)
+	}{
+		{name: "Clean", setup: func(t *testing.T, dir string) {}},
+		{name: "Modified_same_size", setup: func(t *testing.T, dir string) {
+			writeFile(t, filepath.Join(dir, "a.txt"), "A\n")
+		}},
+		{name: "Touched_not_modified", setup: func(t *testing.T, dir string) {
+			later := time.Now().Add(time.Hour)
+			require.NoError(t, os.Chtimes(filepath.Join(dir, "a.txt"), later, later))
+		}},
+		{name: "Staged_and_modified_again", setup: func(t *testing.T, dir string) {
+			writeFile(t, filepath.Join(dir, "a.txt"), "staged\n")
+			gitRun(t, dir, "add", "a.txt")
+			writeFile(t, filepath.Join(dir, "a.txt"), "again\n")
+		}},
+		{name: "Deleted_and_staged_delete", setup: func(t *testing.T, dir string) {
+			require.NoError(t, os.Remove(filepath.Join(dir, "a.txt")))
+			gitRun(t, dir, "rm", "--quiet", "src/b.txt")
+		}},
+		{name: "Staged_rename", setup: func(t *testing.T, dir string) {
+			gitRun(t, dir, "mv", "a.txt", "moved.txt")
+		}},
+		{name: "Staged_rename_modified_again", setup: func(t *testing.T, dir string) {
+			gitRun(t, dir, "mv", "src/b.txt", "b.txt")
+			writeFile(t, filepath.Join(dir, "b.txt"), "changed\n")
+		}},
+		{name: "Unstaged_rename", setup: func(t *testing.T, dir string) {
+			require.NoError(t, os.Rename(filepath.Join(dir, "a.txt"), filepath.Join(dir, "moved.txt")))
+		}},
+		{name: "Staged_new_file", setup: func(t *testing.T, dir string) {
+			writeFile(t, filepath.Join(dir, "src", "new.txt"), "new\n")
+			gitRun(t, dir, "add", "src/new.txt")
+		}},
+		{name: "Untracked_files_and_dirs", setup: func(t *testing.T, dir string) {
+			writeFile(t, filepath.Join(dir, "notes.txt"), "x")
+			writeFile(t, filepath.Join(dir, "src", "c.txt"), "x")
+			writeFile(t, filepath.Join(dir, "docs", "deep", "d.txt"), "x")
+			require.NoError(t, os.MkdirAll(filepath.Join(dir, "empty"), 0755))
+		}},
+		{name: "Ignored", setup: func(t *testing.T, dir string) {
+			writeFile(t, filepath.Join(dir, "build", "out.bin"), "x")
+			writeFile(t, filepath.Join(dir, "src", "debug.log"), "x")
+			writeFile(t, filepath.Join(dir, "logs", "only.log"), "x")
+			writeFile(t, filepath.Join(dir, "src", ".gitignore"), "gen/\n")
+			writeFile(t, filepath.Join(dir, "src", "gen", "x.go"), "x")
+		}},
+		{name: "Merge_conflict", setup: func(t *testing.T, dir string) {
+			gitRun(t, dir, "checkout", "--quiet", "-b", "other")
+			writeFile(t, filepath.Join(dir, "a.txt"), "other\n")
+			gitRun(t, dir, "commit", "--quiet", "-am", "other")
+			gitRun(t, dir, "checkout", "--quiet", "main")
+			writeFile(t, filepath.Join(dir, "a.txt"), "main\n")
+			gitRun(t, dir, "commit", "--quiet", "-am", "main")
+			_, err := util.ExecCmd(dir, "git", "merge", "--quiet", "other")
+			require.Error(t, err)
+		}},
+		{name: "Executable_bit", setup: func(t *testing.T, dir string) {
+			require.NoError(t, os.Chmod(filepath.Join(dir, "a.txt"), 0755))
+		}},
+		{name: "Nested_repo", setup: func(t *testing.T, dir string) {
+			initRepo(t, filepath.Join(dir, "vendor", "lib"), 1)
+		}},
+		{name: "Packed_refs_detached", setup: func(t *testing.T, dir string) {
+			gitRun(t, dir, "pack-refs", "--all")
+			gitRun(t, dir, "checkout", "--quiet", "--detach")
+		}},
+		{name: "Autocrlf_checkout", setup: func(t *testing.T, dir string) {
+			// Checked out with CRLF, and racily clean, so it gets hashed.
+			gitRun(t, dir, "config", "core.autocrlf", "true")
+			require.NoError(t, os.Remove(filepath.Join(dir, "a.txt")))
+			gitRun(t, dir, "checkout", "--", "a.txt")
+		}},
+		{name: "Eol_attribute_checkout", setup: func(t *testing.T, dir string) {
+			writeFile(t, filepath.Join(dir, ".gitattributes"), "*.txt eol=crlf\n")
+			gitRun(t, dir, "add", ".gitattributes")
+			gitRun(t, dir, "commit", "--quiet", "-m", "attributes")
+			require.NoError(t, os.Remove(filepath.Join(dir, "src", "b.txt")))
+			gitRun(t, dir, "checkout", "--", "src/b.txt")
+		}},
+		{name: "Clean_filter", setup: func(t *testing.T, dir string) {
+			gitRun(t, dir, "config", "filter.upper.clean", "tr a-z A-Z")
+			writeFile(t, filepath.Join(dir, "src", ".gitattributes"), "*.txt filter=upper\n")
+			writeFile(t, filepath.Join(dir, "src", "b.txt"), "B\n")
+			gitRun(t, dir, "add", "src")
+			gitRun(t, dir, "commit", "--quiet", "-m", "filter")
+			writeFile(t, filepath.Join(dir, "src", "b.txt"), "b\n")
+		}},
+		{name: "Unborn_branch", setup: func(t *testing.T, dir string) {
+			gitRun(t, dir, "checkout", "--quiet", "--orphan", "fresh")
+			gitRun(t, dir, "rm", "--quiet", "--cached", "-r", ".")
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			initRepo(t, dir, 0)
+			writeFile(t, filepath.Join(dir, "a.txt"), "a\n")
+			writeFile(t, filepath.Join(dir, "src", "b.txt"), "b\n")
+			writeFile(t, filepath.Join(dir, ".gitignore"), "build/\n*.log\n")
+			gitRun(t, dir, "add", ".")
+			gitRun(t, dir, "commit", "--quiet", "-m", "init")
+			// Backdate the index so nothing looks racily clean.
+			past := time.Now().Add(-time.Minute)
+			for _, f := range []string{"a.txt", "src/b.txt", ".gitignore", ".git/index"} {
+				require.NoError(t, os.Chtimes(filepath.Join(dir, f), past, past))
+			}
+			gitRun(t, dir, "update-index", "--refresh")
+
+			tt.setup(t, dir)
+
+			s, err := readGitStatus(dir)
+			require.NoError(t, err)
+			assert.Equal(t, porcelainCount(t, dir), s.changes)
+
+			branch, err := util.ExecCmd(dir, "git", "rev-parse", "--abbrev-ref", "HEAD")
+			if err == nil {
+				assert.Equal(t, branch, s.branch)
+			} else {
+				assert.Equal(t, "fresh", s.branch)
+			}
+		})
+	}
+}
+
+func TestReadGitStatusWorktree(t *testing.T) {
+	wc := testWorkspace(t)
+	initRepo(t, filepath.Join(wc.Path, "api"), 1)
+	wt, err := AddWorktree(wc, "api", "feature/x")
+	require.NoError(t, err)
+	writeFile(t, filepath.Join(wt.Path, "wip.txt"), "x")
+
+	s, err := readGitStatus(wt.Path)
+	require.NoError(t, err)
+	assert.Equal(t, "feature/x", s.branch)
+	assert.Equal(t, 1, s.changes)
+	assert.False(t, s.lastCommit.IsZero())
+}
+
+// benchmarkWorkspace makes a workspace of n repos of 200 tracked files each,
+// a few of them modified and untracked.
+func benchmarkWorkspace(b *testing.B, n int) config.WorkspaceConfig {
+	b.Helper()
+	wc := config.WorkspaceConfig{Name: "bench", Path: b.TempDir()}
+	for i := range n {
+		dir := filepath.Join(wc.Path, fmt.Sprintf("repo%d", i))
+		initRepo(b, dir, 0)
+		for f := range 200 {
+			writeFile(b, filepath.Join(dir, fmt.Sprintf("pkg%d", f%10), fmt.Sprintf("f%d.go", f)), "package x\n")
+		}
+		gitRun(b, dir, "add", ".")
+		gitRun(b, dir, "commit", "--quiet", "-m", "init")
+		writeFile(b, filepath.Join(dir, "pkg0", "f0.go"), "package y\n")
+		writeFile(b, filepath.Join(dir, "scratch.txt"), "x")
+	}
+	return wc
+}
+
+// BenchmarkGitInfo compares loading the picker's git info the old way, a few
+// git processes per repo each in its own goroutine, with the native reader.
+func BenchmarkGitInfo(b *testing.B) {
+	wc := benchmarkWorkspace(b, 20)
+	repos, err := GitProjects(wc)
+	require.NoError(b, err)
+
+	b.Run("Exec", func(b *testing.B) {
+		for range b.N {
+			done := make(chan struct{})
+			for _, repo := range repos {
+				go func() {
+					path := filepath.Join(wc.Path, repo)
+					util.GetGitBranch(wc, repo)
+					util.GetUncommittedChangesCount(wc, repo)
+					util.ExecCmd(path, "git", "rev-list", "--left-right", "--count", "HEAD...@{upstream}")
+					util.ExecCmd(path, "git", "rev-list", "--walk-reflogs", "--count", "refs/stash")
+					util.ExecCmd(path, "git", "log", "-1", "--format=%ct")
+					done <- struct{}{}
+				}()
+			}
+			for range repos {
+				<-done
+			}
+		}
+	})
+
+	b.Run("Native", func(b *testing.B) {
+		for range b.N {
+			loadGitInfo(wc, repos)
+		}
+	})
+}
@@ -0,0 +1,80 @@
+package workspacer
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// gitState is what the picker shows about a repo beyond its branch and
+// uncommitted changes.
+type gitState struct {
+	ahead       int // commits not on the upstream yet
+	behind      int // upstream commits not pulled yet
+	hasUpstream bool
+	stashes     int
+	lastCommit  time.Time
+	detached    bool
+	rebasing    bool
+}
+
+var errNoUpstream = errors.New("no upstream")
+
+// aheadBehind counts the commits HEAD and its upstream each have that the
+// other doesn't.
+func aheadBehind(path string) (ahead, behind int, err error) {
+	r, err := openGitRepo(path)
+	if err != nil {
+		return 0, 0, err
+	}
+	head, err := r.head()
+	if err != nil {
+		return 0, 0, err
+	}
+	up, err := r.resolve(r.upstream(head.branch))
+	if err != nil || head.hash.IsZero() {
+		return 0, 0, errNoUpstream
+	}
+	return r.aheadBehind(head.hash, up)
+}
+
+// summary renders the state for the picker subtitle, e.g.
+// "↑2 ↓1 stash:1 · 3d", with a leading space when not empty.
+func (s gitState) summary(now time.Time) string {
+	var parts []string
+	if s.rebasing {
+		parts = append(parts, warnStyle.Render("rebasing"))
+	}
+	if s.ahead > 0 {
+		parts = append(parts, changesStyle.Render(fmt.Sprintf("↑%d", s.ahead)))
+	}
+	if s.behind > 0 {
+		parts = append(parts, changesStyle.Render(fmt.Sprintf("↓%d", s.behind)))
+	}
+	if s.stashes > 0 {
+		parts = append(parts, fmt.Sprintf("stash:%d", s.stashes))
+	}
+	if !s.lastCommit.IsZero() {
+		parts = append(parts, "· "+formatAge(now.Sub(s.lastCommit)))
+	}
+	if len(parts) == 0 {
+		return ""
+	}
+	return " " + strings.Join(parts, " ")
+}
+
+// formatAge renders a duration the short way the picker shows ages: now, 5m,
+// 3h, 2d.
+func formatAge(d time.Duration) string {
+	switch {
+	case d < time.Minute:
+		return "now"
+	case d < time.Hour:
+		return fmt.Sprintf("%dm", int(d.Minutes()))
+	case d < 24*time.Hour:
+		return fmt.Sprintf("%dh", int(d.Hours()))
+	default:
+		return fmt.Sprintf("%dd", int(d.Hours()/24))
+	}
+}
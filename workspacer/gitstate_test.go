@@ -0,0 +1,109 @@
+package workspacer
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadGitState(t *testing.T) {
+	dir := t.TempDir()
+	remote, local := filepath.Join(dir, "remote"), filepath.Join(dir, "local")
+	initRepo(t, remote, 1)
+	gitRun(t, dir, "clone", "--quiet", remote, local)
+
+	t.Run("Clean_clone", func(t *testing.T) {
+		st, err := readGitStatus(local)
+		require.NoError(t, err)
+		s := st.gitState
+		assert.True(t, s.hasUpstream)
+		assert.Zero(t, s.ahead+s.behind+s.stashes)
+		assert.False(t, s.detached || s.rebasing)
+		assert.WithinDuration(t, time.Now(), s.lastCommit, time.Minute)
+	})
+
+	t.Run("Ahead_behind_and_stashed", func(t *testing.T) {
+		gitRun(t, remote, "commit", "--quiet", "--allow-empty", "-m", "upstream")
+		gitRun(t, local, "commit", "--quiet", "--allow-empty", "-m", "local one")
+		gitRun(t, local, "commit", "--quiet", "--allow-empty", "-m", "local two")
+		gitRun(t, local, "fetch", "--quiet")
+		require.NoError(t, os.WriteFile(filepath.Join(local, "wip.txt"), nil, 0644))
+		gitRun(t, local, "stash", "--quiet", "--include-untracked")
+
+		st, err := readGitStatus(local)
+		require.NoError(t, err)
+		s := st.gitState
+		assert.Equal(t, 2, s.ahead)
+		assert.Equal(t, 1, s.behind)
+		assert.Equal(t, 1, s.stashes)
+	})
+
+	t.Run("Detached_and_rebasing", func(t *testing.T) {
+		gitRun(t, local, "checkout", "--quiet", "--detach")
+		require.NoError(t, os.Mkdir(filepath.Join(local, ".git", "rebase-merge"), 0755))
+
+		st, err := readGitStatus(local)
+		require.NoError(t, err)
+		s := st.gitState
+		assert.True(t, s.detached)
+		assert.True(t, s.rebasing)
+		assert.False(t, s.hasUpstream)
+	})
+}
+
+func TestGitStateSummary(t *testing.T) {
+	now := time.Date(2025, 3, 10, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name  string
+		state gitState
+		want  string
+	}{
+		{name: "Nothing_known", state: gitState{}, want: ""},
+		{
+			name:  "In_sync",
+			state: gitState{hasUpstream: true, lastCommit: now.Add(-90 * time.Minute)},
+			want:  " · 1h",
+		},
+		{
+			name:  "Needs_push_and_pull",
+			state: gitState{ahead: 2, behind: 1, hasUpstream: true, stashes: 3, lastCommit: now.Add(-50 * time.Hour)},
+			want:  " ↑2 ↓1 stash:3 · 2d",
+		},
+		{
+			name:  "Rebasing",
+			state: gitState{rebasing: true, lastCommit: now.Add(-time.Second)},
+			want:  " rebasing · now",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.state.summary(now))
+		})
+	}
+}
+
+func TestCachedGitState(t *testing.T) {
+	committed := time.Date(2025, 3, 10, 12, 0, 0, 0, time.UTC)
+
+	for _, state := range []gitState{{hasUpstream: true}, {ahead: 1, lastCommit: committed}} {
+		cache := &WorkspaceCache{Projects: map[string]ProjectCache{}}
+		cache.UpdateGitInfo("api", repoGitInfo{name: "api", state: state})
+
+		data, err := json.Marshal(cache.Projects["api"])
+		require.NoError(t, err)
+		assert.Equal(t, !state.lastCommit.IsZero(), strings.Contains(string(data), "git_last_commit"),
+			"an unknown last commit is left out")
+
+		var loaded ProjectCache
+		require.NoError(t, json.Unmarshal(data, &loaded))
+		assert.Equal(t, state, loaded.gitState())
+	}
+}
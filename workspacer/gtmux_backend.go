@@ -5,6 +5,8 @@ import (
 	"os"
 	"os/exec"
 	"strings"
+
+	"github.com/JamesTiberiusKirk/workspacer/config"
 )
 
 // gtmuxBackend drives github.com/FyrmForge/gtmux via its CLI. gtmux builds a
@@ -44,6 +46,28 @@ func (b *gtmuxBackend) ListSessions() ([]string, error) {
 	return names, nil
 }
 
+// DescribeSessions reads window counts and attached state from `gtmux list`,
+// whose lines are "<name>: <n> windows", with " (attached)" appended for
+// sessions with a client. gtmux doesn't report activity.
+func (b *gtmuxBackend) DescribeSessions() (map[string]SessionInfo, error) {
+	out, err := exec.Command(b.bin, "list").Output()
+	if err != nil {
+		return nil, err
+	}
+	info := map[string]SessionInfo{}
+	for _, line := range strings.Split(strings.TrimSpace(string(out)), "\n") {
+		name, rest, ok := strings.Cut(strings.TrimSpace(line), ":")
+		if !ok || name == "" {
+			continue
+		}
+		var windows int
+		fmt.Sscanf(strings.TrimSpace(rest), "%d", &windows)
+		attached := strings.Contains(rest, "(attached)")
+		info[name] = SessionInfo{Windows: windows, Attached: &attached}
+	}
+	return info, nil
+}
+
 func (b *gtmuxBackend) KillSession(name string) error {
 	return exec.Command(b.bin, "kill-session", name).Run()
 }
@@ -52,45 +76,67 @@ func (b *gtmuxBackend) CreateSession(spec SessionSpec) error {
 	// Detached create; the session's first window/pane inherits this cwd.
 	newCmd := exec.Command(b.bin, "new", "-d", spec.Name)
 	newCmd.Dir = spec.Path
+	if len(spec.Windows) > 0 {
+		newCmd.Dir = spec.firstPaneDir(spec.Windows[0])
+	}
+	// The first pane is spawned from this process's environment; everything
+	// created afterwards picks the vars up from the session environment.
+	newCmd.Env = append(os.Environ(), spec.envList()...)
 	if err := newCmd.Run(); err != nil {
 		return fmt.Errorf("gtmux new -d %s: %w", spec.Name, err)
 	}
+	for _, k := range sortedKeys(spec.Env) {
+		b.run(spec.Name, "set-environment", k, spec.Env[k])
+	}
+	if spec.Meta.Workspace != "" {
+		b.run(spec.Name, "set-option", "@workspacer_workspace", spec.Meta.Workspace)
+		b.run(spec.Name, "set-option", "@workspacer_project", spec.Meta.Project)
+	}
 
 	for i, w := range spec.Windows {
-		wdir := w.Path
-		if wdir == "" {
-			wdir = spec.Path
-		}
+		// Each pane's ID (%N), so it can be sized once the window is built.
+		paneIDs := make([]string, len(w.Panes))
 		if i == 0 {
 			if w.Name != "" {
 				b.run(spec.Name, "rename-window", w.Name)
 			}
-			// window[0]/pane[0] already exists, rooted at spec.Path.
+			// window[0]/pane[0] already exists, rooted at its first pane's dir.
+			paneIDs[0] = b.output(spec.Name, "display-message", "-p", "#{pane_id}")
 		} else {
-			args := []string{"new-window"}
+			args := []string{"new-window", "-P", "-F", "#{pane_id}"}
 			if w.Name != "" {
 				args = append(args, "-n", w.Name)
 			}
-			args = append(args, "-c", wdir)
-			b.run(spec.Name, args...)
+			args = append(args, "-c", spec.firstPaneDir(w))
+			paneIDs[0] = b.output(spec.Name, args...)
 		}
 
 		for pi, p := range w.Panes {
 			if pi > 0 {
-				b.run(spec.Name, "split-window", "-c", wdir)
+				args := []string{"split-window", "-P", "-F", "#{pane_id}", "-c", spec.paneDir(w, p)}
+				switch p.Orientation {
+				case config.OrientationHorizontal:
+					args = append(args, "-h")
+				case config.OrientationVertical:
+					args = append(args, "-v")
+				}
+				paneIDs[pi] = b.output(spec.Name, args...)
 			}
 			if p.Command != "" {
 				// -l = literal text (avoids key-name lookup), then Enter.
 				b.run(spec.Name, "send-keys", "-l", p.Command)
 				b.run(spec.Name, "send-keys", "Enter")
 			}
-			if p.Size > 0 && p.Size < 100 {
-				b.run(spec.Name, "resize-pane", "-x", fmt.Sprintf("%d%%", p.Size))
-			}
 		}
 		if w.Layout != "" {
 			b.run(spec.Name, "select-layout", w.Layout)
 		}
+
+		// Size panes once every split exists and the layout is applied, as
+		// either would undo an earlier resize.
+		for pi, p := range w.Panes {
+			b.paneSize(spec.Name, paneIDs[pi], p)
+		}
 	}
 
 	// Focus the first window (base-index-independent).
@@ -107,6 +153,49 @@ func (b *gtmuxBackend) Attach(name string) error {
 	return cmd.Run()
 }
 
+// SessionTags reads the @workspacer_* options of each session. gtmux has no
+// list format strings, so this asks every session in turn.
+func (b *gtmuxBackend) SessionTags() (map[string]SessionMeta, error) {
+	names, err := b.ListSessions()
+	if err != nil {
+		return nil, err
+	}
+	var lines []string
+	for _, n := range names {
+		ws, _ := exec.Command(b.bin, "run", n, "show-options", "-v", "@workspacer_workspace").Output()
+		project, _ := exec.Command(b.bin, "run", n, "show-options", "-v", "@workspacer_project").Output()
+		lines = append(lines, n+"\t"+strings.TrimSpace(string(ws))+"\t"+strings.TrimSpace(string(project)))
+	}
+	return parseSessionTags(strings.Join(lines, "\n")), nil
+}
+
+// paneSize applies p's width and height to the pane with the given ID. A
+// pane whose ID couldn't be read is left at the layout's size.
+func (b *gtmuxBackend) paneSize(session, id string, p PaneSpec) {
+	if id == "" || (p.Width == "" && p.Height == "") {
+		return
+	}
+	args := []string{"resize-pane", "-t", id}
+	if p.Width != "" {
+		args = append(args, "-x", p.Width)
+	}
+	if p.Height != "" {
+		args = append(args, "-y", p.Height)
+	}
+	b.run(session, args...)
+}
+
+// output is run returning the command's trimmed stdout, "" on failure.
+func (b *gtmuxBackend) output(session string, args ...string) string {
+	full := append([]string{"run", session}, args...)
+	out, err := exec.Command(b.bin, full...).Output()
+	if err != nil {
+		fmt.Printf("gtmux run %s %s: %v\n", session, strings.Join(args, " "), err)
+		return ""
+	}
+	return strings.TrimSpace(string(out))
+}
+
 // run executes `gtmux run <session> <args...>` best-effort, printing any error
 // (mirrors the tmux backend's tolerance of per-step failures during build).
 func (b *gtmuxBackend) run(session string, args ...string) {
@@ -0,0 +1,71 @@
+package workspacer
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/JamesTiberiusKirk/workspacer/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeGtmux puts a gtmux script on GTMUX_BIN that logs every call and
+// answers pane ID queries with %0, %1, ... It returns the log's path.
+func fakeGtmux(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	log := filepath.Join(dir, "calls")
+	script := `#!/bin/sh
+echo "$*" >> ` + log + `
+case "$*" in
+*pane_id*)
+	n=$(cat ` + dir + `/panes 2>/dev/null || echo 0)
+	echo "%$n"
+	echo $((n + 1)) > ` + dir + `/panes
+	;;
+esac
+`
+	bin := filepath.Join(dir, "gtmux")
+	require.NoError(t, os.WriteFile(bin, []byte(script), 0755))
+	t.Setenv("GTMUX_BIN", bin)
+	return log
+}
+
+func TestGtmuxCreateSession(t *testing.T) {
+	log := fakeGtmux(t)
+	dir := t.TempDir()
+
+	err := newGtmuxBackend().CreateSession(SessionSpec{
+		Name: "wk-api",
+		Path: dir,
+		Windows: []WindowSpec{
+			{Name: "editor", Layout: "main-vertical", Panes: []PaneSpec{
+				{Command: "nvim", Width: "60%"},
+				{Orientation: config.OrientationVertical, Height: "30%"},
+			}},
+			{Name: "server", Panes: []PaneSpec{{Command: "go run .", Height: "15"}}},
+		},
+	})
+	require.NoError(t, err)
+
+	calls, err := os.ReadFile(log)
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		"new -d wk-api",
+		"run wk-api rename-window editor",
+		"run wk-api display-message -p #{pane_id}",
+		"run wk-api send-keys -l nvim",
+		"run wk-api send-keys Enter",
+		"run wk-api split-window -P -F #{pane_id} -c " + dir + " -v",
+		"run wk-api select-layout main-vertical",
+		"run wk-api resize-pane -t %0 -x 60%",
+		"run wk-api resize-pane -t %1 -y 30%",
+		"run wk-api new-window -P -F #{pane_id} -n server -c " + dir,
+		"run wk-api send-keys -l go run .",
+		"run wk-api send-keys Enter",
+		"run wk-api resize-pane -t %2 -y 15",
+		"run wk-api select-window {start}",
+	}, strings.Split(strings.TrimSpace(string(calls)), "\n"), "panes are sized after the splits and layout that would undo it")
+}
@@ -0,0 +1,91 @@
+package workspacer
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/JamesTiberiusKirk/workspacer/config"
+	"github.com/JamesTiberiusKirk/workspacer/state"
+)
+
+// Session lifecycle events hooks can be declared for.
+const (
+	HookPreCreate  = "pre_create"
+	HookPostCreate = "post_create"
+	HookPreAttach  = "pre_attach"
+	HookPostKill   = "post_kill"
+)
+
+// hookTarget is the session a hook runs for. Its fields are exported to the
+// hook as WORKSPACER_* environment variables.
+type hookTarget struct {
+	Workspace string
+	Project   string
+	Session   string
+	Path      string
+}
+
+func (t hookTarget) env() []string {
+	return []string{
+		"WORKSPACER_WORKSPACE=" + t.Workspace,
+		"WORKSPACER_PROJECT=" + t.Project,
+		"WORKSPACER_SESSION=" + t.Session,
+		"WORKSPACER_PATH=" + t.Path,
+	}
+}
+
+// mergeHooks concatenates hook lists, workspace hooks first, then preset ones.
+func mergeHooks(hooks ...config.HooksConfig) config.HooksConfig {
+	merged := config.HooksConfig{}
+	for _, h := range hooks {
+		merged.PreCreate = append(merged.PreCreate, h.PreCreate...)
+		merged.PostCreate = append(merged.PostCreate, h.PostCreate...)
+		merged.PreAttach = append(merged.PreAttach, h.PreAttach...)
+		merged.PostKill = append(merged.PostKill, h.PostKill...)
+	}
+	return merged
+}
+
+// runHooks runs the hooks for event, in order, through `sh -c` in the
+// target's path. It stops at the first failure and returns it; callers decide
+// whether that aborts (pre_create) or is only reported. Under --dry-run hooks
+// are printed, not run.
+func runHooks(hooks config.HooksConfig, event string, t hookTarget) error {
+	var cmds []string
+	switch event {
+	case HookPreCreate:
+		cmds = hooks.PreCreate
+	case HookPostCreate:
+		cmds = hooks.PostCreate
+	case HookPreAttach:
+		cmds = hooks.PreAttach
+	case HookPostKill:
+		cmds = hooks.PostKill
+	}
+
+	for _, c := range cmds {
+		if state.DryRun {
+			fmt.Printf("[dry-run] %s hook for %s: %s\n", event, t.Session, c)
+			continue
+		}
+
+		cmd := exec.Command("sh", "-c", c)
+		cmd.Dir = t.Path
+		cmd.Env = append(os.Environ(), t.env()...)
+		cmd.Stdout, cmd.Stderr = os.Stdout, os.Stderr
+		if err := cmd.Run(); err != nil {
+			return fmt.Errorf("%s hook %q for %s failed: %w", event, c, t.Session, err)
+		}
+	}
+	return nil
+}
+
+// reportHooks runs hooks whose failure must not stop the caller, printing any
+// error instead.
+func reportHooks(hooks config.HooksConfig, event string, t hookTarget) {
+	if err := runHooks(hooks, event, t); err != nil {
+		fmt.Println(strings.TrimSpace(err.Error()))
+	}
+}
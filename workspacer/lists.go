@@ -4,9 +4,9 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"runtime"
 	"sort"
 	"strings"
-	"sync"
 	"time"
 
 	"github.com/JamesTiberiusKirk/workspacer/config"
@@ -22,6 +22,7 @@ var (
 	branchStyle       = lipgloss.NewStyle().Foreground(lipgloss.Color("12")) // Blue
 	changesStyle      = lipgloss.NewStyle().Foreground(lipgloss.Color("11")) // Yellow
 	changesCleanStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("10")) // Green
+	warnStyle         = lipgloss.NewStyle().Foreground(lipgloss.Color("9"))  // Red
 )
 
 // sisterGitInfo holds git information for a sister repository
@@ -36,53 +37,59 @@ type repoGitInfo struct {
 	name         string
 	branch       string
 	changesCount int
+	state        gitState
 	sisters      []sisterGitInfo
 	hasError     bool
 }
 
 // loadGitInfoForRepo loads git information for a single repository
-func loadGitInfoForRepo(wc config.WorkspaceConfig, repoName string, gitInfoChan chan<- repoGitInfo, wg *sync.WaitGroup) {
-	defer wg.Done()
-
+func loadGitInfoForRepo(wc config.WorkspaceConfig, repoName string) repoGitInfo {
 	info := repoGitInfo{
 		name: repoName,
 	}
 
-	// Get git branch
-	info.branch = util.GetGitBranch(wc, repoName)
-	if info.branch == "" {
+	st, err := readGitStatus(filepath.Join(util.GetWorkspacePath(wc), repoName))
+	if err != nil {
 		info.hasError = true
 	}
-
-	// Get uncommitted changes count
-	info.changesCount = util.GetUncommittedChangesCount(wc, repoName)
+	info.branch = st.branch
+	info.changesCount = st.changes
+	info.state = st.gitState
 
 	// Check for sister repos
 	sisterRepos := util.GetSisterReposForProject(wc, repoName)
 	for _, sr := range sisterRepos {
 		if util.DoesProjectExist(wc, sr.Name) {
-			sisterInfo := sisterGitInfo{
+			sst, _ := readGitStatus(filepath.Join(util.GetWorkspacePath(wc), sr.Name))
+			info.sisters = append(info.sisters, sisterGitInfo{
 				label:   sr.Label,
-				branch:  util.GetGitBranch(wc, sr.Name),
-				changes: util.GetUncommittedChangesCount(wc, sr.Name),
-			}
-			info.sisters = append(info.sisters, sisterInfo)
+				branch:  sst.branch,
+				changes: sst.changes,
+			})
 		}
 	}
 
-	gitInfoChan <- info
+	return info
+}
+
+// loadGitInfo loads the git information of repos, one per CPU at a time.
+func loadGitInfo(wc config.WorkspaceConfig, repos []string) []repoGitInfo {
+	infos := make([]repoGitInfo, len(repos))
+	parallel(len(repos), runtime.NumCPU(), func(i int) {
+		infos[i] = loadGitInfoForRepo(wc, repos[i])
+	})
+	return infos
 }
 
 func ChooseFromOpenWorkspaceProjectsAndSwitch(workspace string, workspaceConfig config.WorkspaceConfig, sessionPresets map[string]config.SessionConfig) {
-	openProjects := util.GetOpenProjectsByWorkspace(workspace)
+	openProjects := GetOpenProjectsByWorkspace(workspaceConfig)
 	if len(openProjects) == 0 {
 		log.Info("No open projects in workspace %s", workspace)
 	}
 
 	lists := []list.Item{}
 	for _, p := range openProjects {
-		display := strings.TrimPrefix(p, workspace+"-")
-		lists = append(lists, list.Item{Display: display, Value: p})
+		lists = append(lists, list.Item{Display: p, Value: p})
 	}
 	item, found, err := list.NewList("Open projects in workspace: "+workspaceConfig.Name, lists, "", nil)
 	if err != nil {
@@ -101,9 +108,7 @@ func ChooseFromOpenWorkspaceProjectsAndSwitch(workspace string, workspaceConfig
 		if windowSize == 0 {
 			windowSize = 50 // Default
 		}
-		// Extract project name from display (removes workspace prefix)
-		projectName := strings.TrimPrefix(item.Value, workspace+"-")
-		cache.RecordAccess(projectName, windowSize)
+		cache.RecordAccess(item.Value, windowSize)
 		if err := SaveCache(workspaceConfig, cache); err != nil {
 			log.Error("Failed to save usage tracking: %s", err.Error())
 		}
@@ -126,10 +131,39 @@ func removeRepoFromArray(repos []string, name string) []string {
 	return res
 }
 
+// worktreeItems lists a project's linked worktrees as "project@branch".
+func worktreeItems(wc config.WorkspaceConfig, project string, openProjects []string) []list.Item {
+	projectPath := filepath.Join(util.GetWorkspacePath(wc), project)
+	if !hasWorktrees(projectPath) {
+		return nil
+	}
+	worktrees, err := ListWorktrees(projectPath)
+	if err != nil {
+		log.Debug("%s", err.Error())
+		return nil
+	}
+
+	items := []list.Item{}
+	for _, wt := range worktrees {
+		name := project + "@" + wt.Name()
+		item := list.Item{
+			Display:  name,
+			Value:    "folder:" + name,
+			Subtitle: "Worktree: " + branchStyle.Render(wt.Name()) + " " + wt.Path,
+		}
+		if util.Contains(openProjects, name) {
+			item.Display = item.Display + " (Active)"
+			item.IsActive = true
+		}
+		items = append(items, item)
+	}
+	return items
+}
+
 func buildWorkspaceItems(workspace string, wc config.WorkspaceConfig, extraOptions []list.Item) ([]list.Item, string, bool) {
 	cache := LoadCache(wc)
 
-	openProjects := util.GetOpenProjectsByWorkspace(workspace)
+	openProjects := GetOpenProjectsByWorkspace(wc)
 	path := util.GetWorkspacePath(wc)
 	entries, err := os.ReadDir(path)
 	if err != nil {
@@ -140,7 +174,7 @@ func buildWorkspaceItems(workspace string, wc config.WorkspaceConfig, extraOptio
 	// Collect git repos that need info loading
 	var gitRepos []string
 	for _, e := range entries {
-		if !e.IsDir() {
+		if !e.IsDir() || e.Name() == worktreeDir {
 			continue
 		}
 		if util.IsSisterRepo(wc, e.Name()) {
@@ -170,6 +204,7 @@ func buildWorkspaceItems(workspace string, wc config.WorkspaceConfig, extraOptio
 						name:         repoName,
 						branch:       projectCache.GitBranch,
 						changesCount: projectCache.GitChanges,
+						state:        projectCache.gitState(),
 						sisters:      sisters,
 					}
 					gitInfoMap[repoName] = info
@@ -178,23 +213,15 @@ func buildWorkspaceItems(workspace string, wc config.WorkspaceConfig, extraOptio
 		}
 
 		if !useCache || len(gitInfoMap) < len(gitRepos) {
-			var wg sync.WaitGroup
-			gitInfoChan := make(chan repoGitInfo, len(gitRepos))
-
+			var missing []string
 			for _, repoName := range gitRepos {
 				if _, exists := gitInfoMap[repoName]; exists && useCache {
 					continue
 				}
-				wg.Add(1)
-				go loadGitInfoForRepo(wc, repoName, gitInfoChan, &wg)
+				missing = append(missing, repoName)
 			}
 
-			go func() {
-				wg.Wait()
-				close(gitInfoChan)
-			}()
-
-			for info := range gitInfoChan {
+			for _, info := range loadGitInfo(wc, missing) {
 				gitInfoMap[info.name] = info
 				cache.UpdateGitInfo(info.name, info)
 			}
@@ -223,7 +250,7 @@ func buildWorkspaceItems(workspace string, wc config.WorkspaceConfig, extraOptio
 	// Build list items
 	folders := []list.Item{}
 	for _, e := range entries {
-		if !e.IsDir() {
+		if !e.IsDir() || e.Name() == worktreeDir {
 			continue
 		}
 		if util.IsSisterRepo(wc, e.Name()) {
@@ -239,12 +266,17 @@ func buildWorkspaceItems(workspace string, wc config.WorkspaceConfig, extraOptio
 		if info, hasGitInfo := gitInfoMap[e.Name()]; hasGitInfo {
 			subtitle := "Service: "
 			if info.branch != "" {
-				subtitle += branchStyle.Render(info.branch)
+				if info.state.detached {
+					subtitle += warnStyle.Render("detached")
+				} else {
+					subtitle += branchStyle.Render(info.branch)
+				}
 				if info.changesCount > 0 {
 					subtitle += " " + changesStyle.Render(fmt.Sprintf("(%d)", info.changesCount))
 				} else {
 					subtitle += " " + changesCleanStyle.Render("✓")
 				}
+				subtitle += info.state.summary(time.Now())
 			} else if info.hasError {
 				subtitle += "(error loading git info)"
 			}
@@ -272,6 +304,7 @@ func buildWorkspaceItems(workspace string, wc config.WorkspaceConfig, extraOptio
 		}
 
 		folders = append(folders, item)
+		folders = append(folders, worktreeItems(wc, e.Name(), openProjects)...)
 	}
 
 	// Sort
@@ -303,11 +336,12 @@ func buildWorkspaceItems(workspace string, wc config.WorkspaceConfig, extraOptio
 	}
 
 	// Add remote repos section
+	forgeName := GetForge(wc).Name()
 	if wc.EnableRemoteRepos && !remoteError && len(remoteRepos) == 0 {
 		folders = append(folders, list.Item{
 			Display:  "No remote repositories found",
 			Value:    "error:no-remote-repos",
-			Subtitle: "No repositories found on GitHub for this workspace",
+			Subtitle: "No repositories found on " + forgeName + " for this workspace",
 		})
 	}
 
@@ -315,15 +349,15 @@ func buildWorkspaceItems(workspace string, wc config.WorkspaceConfig, extraOptio
 		folders = append(folders, list.Item{
 			Display:  remoteRepo,
 			Value:    "git:" + remoteRepo,
-			Subtitle: "Clone From GitHub",
+			Subtitle: "Clone From " + forgeName,
 		})
 	}
 
 	if remoteError {
 		folders = append(folders, list.Item{
-			Display:  "⚠ GitHub repos unavailable",
+			Display:  "⚠ " + forgeName + " repos unavailable",
 			Value:    "error:github",
-			Subtitle: "Check network connection or GITHUB_AUTH token",
+			Subtitle: "Check network connection or " + forgeName + " token",
 		})
 	}
 
@@ -354,15 +388,7 @@ func buildWorkspaceItems(workspace string, wc config.WorkspaceConfig, extraOptio
 		if repoCount > 0 {
 			parts = append(parts, fmt.Sprintf("%d gh", repoCount))
 		}
-		var ageStr string
-		if ago < time.Minute {
-			ageStr = "now"
-		} else if ago < time.Hour {
-			ageStr = fmt.Sprintf("%dm", int(ago.Minutes()))
-		} else {
-			ageStr = fmt.Sprintf("%dh", int(ago.Hours()))
-		}
-		parts = append(parts, ageStr)
+		parts = append(parts, formatAge(ago))
 		cacheStatus = "cache: " + strings.Join(parts, " ")
 	}
 
@@ -0,0 +1,154 @@
+package workspacer
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/JamesTiberiusKirk/workspacer/config"
+	"github.com/mattn/go-isatty"
+)
+
+const trustFileName = "trusted_manifests.json"
+
+// TrustedManifest is an allowlist entry: the content of a manifest the user
+// agreed to run, keyed in the trust file by the manifest's absolute path.
+// Trust is for that file in that repo only: editing it changes its hash, and
+// the same content in another repo (where its hooks could run different
+// scripts) has to be trusted on its own.
+type TrustedManifest struct {
+	SHA256    string    `json:"sha256"`
+	TrustedAt time.Time `json:"trusted_at"`
+}
+
+// confirmTrust asks the user whether to run the manifest at path. changed is
+// set when an earlier version of it was trusted. Swapped out in tests.
+var confirmTrust = func(path string, content []byte, changed bool) bool {
+	if !isatty.IsTerminal(os.Stdin.Fd()) {
+		fmt.Printf("Ignoring untrusted %s, open the project from a terminal to review it\n", path)
+		return false
+	}
+
+	if changed {
+		fmt.Printf("\n%s has changed since you trusted it:\n\n", path)
+	} else {
+		fmt.Printf("\n%s sets up this session with:\n\n", path)
+	}
+	fmt.Println(strings.TrimRight(string(content), "\n"))
+	fmt.Print("\nIt can run commands. Trust it? [y/N] ")
+
+	answer, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+	answer = strings.ToLower(strings.TrimSpace(answer))
+	return answer == "y" || answer == "yes"
+}
+
+// loadManifest reads the .workspacer.yaml in dir. It's only used once the
+// user has trusted its exact content; with ask set they're asked when they
+// haven't yet.
+func loadManifest(dir string, ask bool) (config.ProjectManifest, bool) {
+	path := filepath.Join(dir, config.ManifestFile)
+	if abs, err := filepath.Abs(path); err == nil {
+		path = abs
+	}
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return config.ProjectManifest{}, false
+	}
+
+	m, err := config.ParseProjectManifest(content)
+	if err != nil {
+		fmt.Printf("Ignoring %s: %s\n", path, err)
+		return config.ProjectManifest{}, false
+	}
+
+	trusted := loadTrustedManifests()
+	hash := manifestHash(content)
+	entry, known := trusted[path]
+	if known && entry.SHA256 == hash {
+		return m, true
+	}
+
+	if !ask {
+		return config.ProjectManifest{}, false
+	}
+	if !confirmTrust(path, content, known) {
+		return config.ProjectManifest{}, false
+	}
+
+	trusted[path] = TrustedManifest{SHA256: hash, TrustedAt: time.Now()}
+	if err := saveTrustedManifests(trusted); err != nil {
+		fmt.Printf("Failed to remember trusting %s: %s\n", path, err)
+	}
+	return m, true
+}
+
+func manifestHash(content []byte) string {
+	sum := sha256.Sum256(content)
+	return hex.EncodeToString(sum[:])
+}
+
+// trustFilePath is where the allowlist lives, next to the config file rather
+// than in any repo a manifest could come from.
+func trustFilePath() (string, error) {
+	configPath, err := config.GetDefaultConfigPath()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(filepath.Dir(configPath), trustFileName), nil
+}
+
+func loadTrustedManifests() map[string]TrustedManifest {
+	trusted := map[string]TrustedManifest{}
+	path, err := trustFilePath()
+	if err != nil {
+		return trusted
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return trusted
+	}
+	if err := json.Unmarshal(data, &trusted); err != nil {
+		fmt.Printf("Failed to parse %s, no manifests are trusted: %s\n", path, err)
+		return map[string]TrustedManifest{}
+	}
+	return trusted
+}
+
+func saveTrustedManifests(trusted map[string]TrustedManifest) error {
+	path, err := trustFilePath()
+	if err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(trusted, "", "\t")
+	if err != nil {
+		return fmt.Errorf("failed to marshal trusted manifests: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0600)
+}
+
+// mergeSisterRepos adds the manifest's sister repos to the configured ones; a
+// configured repo of the same name wins.
+func mergeSisterRepos(configured, manifest []config.SisterRepoConfig) []config.SisterRepoConfig {
+	merged := append([]config.SisterRepoConfig{}, configured...)
+	for _, sr := range manifest {
+		dup := false
+		for _, c := range configured {
+			if c.Name == sr.Name {
+				dup = true
+			}
+		}
+		if !dup {
+			merged = append(merged, sr)
+		}
+	}
+	return merged
+}
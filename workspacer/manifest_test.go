@@ -0,0 +1,135 @@
+package workspacer
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/JamesTiberiusKirk/workspacer/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// answerTrust makes the trust prompt answer with trust, counting how often
+// it's asked and whether it was told the manifest changed.
+func answerTrust(t *testing.T, trust bool) (asked *int, changed *bool) {
+	t.Helper()
+	t.Setenv("HOME", t.TempDir())
+	asked, changed = new(int), new(bool)
+	orig := confirmTrust
+	confirmTrust = func(_ string, _ []byte, c bool) bool {
+		*asked++
+		*changed = c
+		return trust
+	}
+	t.Cleanup(func() { confirmTrust = orig })
+	return asked, changed
+}
+
+func TestLoadManifest(t *testing.T) {
+	t.Run("Trusts_each_version_once", func(t *testing.T) {
+		asked, changed := answerTrust(t, true)
+		dir := t.TempDir()
+		path := filepath.Join(dir, config.ManifestFile)
+		require.NoError(t, os.WriteFile(path, []byte("env:\n  A: \"1\"\n"), 0644))
+
+		m, ok := loadManifest(dir, true)
+		require.True(t, ok)
+		assert.Equal(t, map[string]string{"A": "1"}, m.Env)
+		_, ok = loadManifest(dir, true)
+		assert.True(t, ok)
+		assert.Equal(t, 1, *asked, "a trusted manifest isn't asked about again")
+		assert.False(t, *changed)
+
+		require.NoError(t, os.WriteFile(path, []byte("env:\n  A: \"2\"\n"), 0644))
+		_, ok = loadManifest(dir, false)
+		assert.False(t, ok, "an edited manifest needs trusting again")
+		_, ok = loadManifest(dir, true)
+		assert.True(t, ok)
+		assert.Equal(t, 2, *asked)
+		assert.True(t, *changed)
+	})
+
+	t.Run("Trust_is_per_repo", func(t *testing.T) {
+		asked, changed := answerTrust(t, true)
+		content := []byte("hooks:\n  post_create: [./scripts/setup.sh]\n")
+		trustedDir, otherDir := t.TempDir(), t.TempDir()
+		require.NoError(t, os.WriteFile(filepath.Join(trustedDir, config.ManifestFile), content, 0644))
+		require.NoError(t, os.WriteFile(filepath.Join(otherDir, config.ManifestFile), content, 0644))
+
+		_, ok := loadManifest(trustedDir, true)
+		require.True(t, ok)
+		_, ok = loadManifest(otherDir, false)
+		assert.False(t, ok, "the same content in another repo isn't trusted")
+		_, ok = loadManifest(otherDir, true)
+		assert.True(t, ok)
+		assert.Equal(t, 2, *asked)
+		assert.False(t, *changed)
+		_, ok = loadManifest(trustedDir, false)
+		assert.True(t, ok, "trusting it elsewhere keeps the first repo's trust")
+	})
+
+	t.Run("Declined_manifest_is_ignored", func(t *testing.T) {
+		asked, _ := answerTrust(t, false)
+		dir := t.TempDir()
+		require.NoError(t, os.WriteFile(filepath.Join(dir, config.ManifestFile), []byte("env:\n  A: \"1\"\n"), 0644))
+
+		_, ok := loadManifest(dir, true)
+		assert.False(t, ok)
+		_, ok = loadManifest(dir, true)
+		assert.False(t, ok)
+		assert.Equal(t, 2, *asked, "declining isn't remembered")
+	})
+
+	t.Run("Invalid_manifest_is_ignored", func(t *testing.T) {
+		asked, _ := answerTrust(t, true)
+		dir := t.TempDir()
+		require.NoError(t, os.WriteFile(filepath.Join(dir, config.ManifestFile), []byte("windows: []\n"), 0644))
+
+		_, ok := loadManifest(dir, true)
+		assert.False(t, ok)
+		assert.Zero(t, *asked)
+	})
+}
+
+func TestStartOrSwitchToSessionWithManifest(t *testing.T) {
+	answerTrust(t, true)
+	rec := useRecorder(t)
+	wc := testWorkspace(t, "api", "api-docs")
+	wc.Session = &config.SessionConfig{
+		Windows: []config.WindowConfig{
+			{Name: "editor", Panes: []config.PanesConfig{{Command: "nvim"}}},
+			{Name: "shell", Panes: []config.PanesConfig{{}}},
+		},
+		Env: map[string]string{"STAGE": "dev"},
+	}
+	require.NoError(t, os.WriteFile(filepath.Join(wc.Path, "api", config.ManifestFile), []byte(`
+screens:
+  - name: shell
+    merge: replace
+    panes:
+      - command: make watch
+  - name: db
+    panes:
+      - command: psql
+env:
+  STAGE: test
+  DB: local
+sister_repos:
+  - name: api-docs
+    label: docs
+`), 0644))
+
+	StartOrSwitchToSession(wc, nil, "api")
+
+	require.Len(t, rec.Specs, 1)
+	spec := rec.Specs[0]
+	names := []string{}
+	for _, w := range spec.Windows {
+		names = append(names, w.Name)
+	}
+	assert.Equal(t, []string{"api", "shell", "db", "docs"}, names)
+	assert.Equal(t, []PaneSpec{{Command: "make watch"}}, spec.Windows[1].Panes)
+	assert.Equal(t, "test", spec.Env["STAGE"])
+	assert.Equal(t, "local", spec.Env["DB"])
+}
@@ -0,0 +1,191 @@
+package workspacer
+
+import (
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+)
+
+// RecordedCall is one mutating call made against a RecordingBackend.
+type RecordedCall struct {
+	Op   string // "create", "attach" or "kill"
+	Name string
+}
+
+// RecordingBackend records every CreateSession/Attach/KillSession instead of
+// driving a multiplexer. It backs --dry-run (printing each call to Out) and
+// lets the orchestration in tmux.go be tested without tmux.
+//
+// Reads (HasSession/ListSessions) go to Base when set, so a dry run sees the
+// sessions that really exist; otherwise they reflect Existing plus whatever
+// was created through the recorder.
+type RecordingBackend struct {
+	Base     SessionBackend
+	Out      io.Writer
+	Existing []string
+
+	Specs []SessionSpec
+	Calls []RecordedCall
+}
+
+// NewRecordingBackend returns a recorder reading from base (may be nil) and
+// printing to out (may be nil).
+func NewRecordingBackend(base SessionBackend, out io.Writer) *RecordingBackend {
+	return &RecordingBackend{Base: base, Out: out}
+}
+
+func (b *RecordingBackend) HasSession(name string) bool {
+	names, err := b.ListSessions()
+	if err != nil {
+		return false
+	}
+	return slices.Contains(names, name)
+}
+
+func (b *RecordingBackend) ListSessions() ([]string, error) {
+	var names []string
+	if b.Base != nil {
+		base, err := b.Base.ListSessions()
+		if err != nil {
+			return nil, err
+		}
+		names = append(names, base...)
+	}
+	names = append(names, b.Existing...)
+	for _, s := range b.Specs {
+		names = append(names, s.Name)
+	}
+
+	// Sessions killed through the recorder no longer exist.
+	var live []string
+	for _, n := range names {
+		if !b.killed(n) && !slices.Contains(live, n) {
+			live = append(live, n)
+		}
+	}
+	return live, nil
+}
+
+func (b *RecordingBackend) KillSession(name string) error {
+	b.Calls = append(b.Calls, RecordedCall{Op: "kill", Name: name})
+	b.printf("[dry-run] kill session %s\n", name)
+	return nil
+}
+
+func (b *RecordingBackend) CreateSession(spec SessionSpec) error {
+	b.Specs = append(b.Specs, spec)
+	b.Calls = append(b.Calls, RecordedCall{Op: "create", Name: spec.Name})
+	if b.Out != nil {
+		fmt.Fprint(b.Out, FormatSessionSpec(spec))
+	}
+	return nil
+}
+
+func (b *RecordingBackend) Attach(name string) error {
+	b.Calls = append(b.Calls, RecordedCall{Op: "attach", Name: name})
+	b.printf("[dry-run] attach %s\n", name)
+	return nil
+}
+
+// CaptureSession passes through to Base, so snapshots work under --dry-run.
+func (b *RecordingBackend) CaptureSession(name string) (SessionSnapshot, error) {
+	if c, ok := b.Base.(SessionCapturer); ok {
+		return c.CaptureSession(name)
+	}
+	return SessionSnapshot{}, fmt.Errorf("backend does not support snapshots")
+}
+
+// DescribeSessions passes through to Base; sessions created through the
+// recorder have no details to report.
+func (b *RecordingBackend) DescribeSessions() (map[string]SessionInfo, error) {
+	if d, ok := b.Base.(SessionDescriber); ok {
+		return d.DescribeSessions()
+	}
+	return map[string]SessionInfo{}, nil
+}
+
+// SessionTags returns Base's tags plus those of the sessions created through
+// the recorder.
+func (b *RecordingBackend) SessionTags() (map[string]SessionMeta, error) {
+	tags := map[string]SessionMeta{}
+	if b.Base != nil {
+		tags = sessionTags(b.Base)
+	}
+	for _, s := range b.Specs {
+		if s.Meta.Workspace != "" && !b.killed(s.Name) {
+			tags[s.Name] = s.Meta
+		}
+	}
+	return tags, nil
+}
+
+func (b *RecordingBackend) killed(name string) bool {
+	// Only a kill after the latest create counts.
+	for i := len(b.Calls) - 1; i >= 0; i-- {
+		if b.Calls[i].Name != name {
+			continue
+		}
+		switch b.Calls[i].Op {
+		case "kill":
+			return true
+		case "create":
+			return false
+		}
+	}
+	return false
+}
+
+func (b *RecordingBackend) printf(format string, args ...any) {
+	if b.Out != nil {
+		fmt.Fprintf(b.Out, format, args...)
+	}
+}
+
+// FormatSessionSpec renders spec as an indented, human-readable tree of
+// windows and panes, as printed by --dry-run.
+func FormatSessionSpec(spec SessionSpec) string {
+	var sb strings.Builder
+
+	sb.WriteString(fmt.Sprintf("[dry-run] create session %s (%s)\n", spec.Name, spec.Path))
+	for _, kv := range spec.envList() {
+		sb.WriteString(fmt.Sprintf("  env %s\n", kv))
+	}
+	for i, w := range spec.Windows {
+		dir := w.Path
+		if dir == "" {
+			dir = spec.Path
+		}
+		name := w.Name
+		if name == "" {
+			name = "<unnamed>"
+		}
+		layout := w.Layout
+		if layout == "" {
+			layout = "default"
+		}
+		sb.WriteString(fmt.Sprintf("  window %d: %s  layout=%s  cwd=%s\n", i+1, name, layout, dir))
+
+		for pi, p := range w.Panes {
+			cmd := p.Command
+			if cmd == "" {
+				cmd = "<shell>"
+			}
+			sb.WriteString(fmt.Sprintf("    pane %d: %s", pi+1, cmd))
+			if pi > 0 && p.Orientation != "" {
+				sb.WriteString(fmt.Sprintf("  split=%s", p.Orientation))
+			}
+			if p.Width != "" {
+				sb.WriteString(fmt.Sprintf("  width=%s", p.Width))
+			}
+			if p.Height != "" {
+				sb.WriteString(fmt.Sprintf("  height=%s", p.Height))
+			}
+			if p.Path != "" && p.Path != dir {
+				sb.WriteString(fmt.Sprintf("  cwd=%s", p.Path))
+			}
+			sb.WriteString("\n")
+		}
+	}
+	return sb.String()
+}
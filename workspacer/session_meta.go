@@ -0,0 +1,115 @@
+package workspacer
+
+import (
+	"strings"
+
+	"github.com/JamesTiberiusKirk/workspacer/config"
+	"github.com/JamesTiberiusKirk/workspacer/log"
+)
+
+// SessionMeta is the workspace and project a session was created for. Backends
+// store it on the session itself (tmux/gtmux user options @workspacer_*), so
+// it doesn't have to be parsed back out of the session name.
+type SessionMeta struct {
+	Workspace string `json:"workspace"` // key of the workspace in the config
+	Project   string `json:"project"`
+}
+
+// SessionTagger is implemented by backends that tag the sessions they create
+// with their SessionSpec.Meta and can read the tags back. zellij has nowhere
+// to keep them, so its sessions are always matched by name.
+type SessionTagger interface {
+	// SessionTags returns the tags of every tagged session, by session name.
+	SessionTags() (map[string]SessionMeta, error)
+}
+
+// sessionTags returns be's session tags, or none when be can't tag sessions.
+func sessionTags(be SessionBackend) map[string]SessionMeta {
+	tagger, ok := be.(SessionTagger)
+	if !ok {
+		return map[string]SessionMeta{}
+	}
+	tags, err := tagger.SessionTags()
+	if err != nil {
+		log.Debug("could not read session tags: %s", err.Error())
+		return map[string]SessionMeta{}
+	}
+	return tags
+}
+
+// CurrentSessionMeta reports the session this process is running inside along
+// with its tags, read from the multiplexer the session was detected in rather
+// than the WORKSPACER_MUX one. meta is zero when the session isn't tagged
+// (created by an older workspacer, or by hand).
+func CurrentSessionMeta() (name string, meta SessionMeta, ok bool) {
+	name, mux, ok := currentSession()
+	if !ok {
+		return "", SessionMeta{}, false
+	}
+	return name, sessionTags(backendFor(mux))[name], true
+}
+
+// openSession is a session belonging to a workspace.
+type openSession struct {
+	Name    string
+	Project string
+}
+
+// workspaceSessions returns the open sessions of wc. Tagged sessions are
+// matched on their workspace tag, the workspace's key (or its name, which
+// older versions tagged with); untagged ones fall back to the
+// "<prefix>-<project>" session name.
+func workspaceSessions(be SessionBackend, wc config.WorkspaceConfig) ([]openSession, error) {
+	names, err := be.ListSessions()
+	if err != nil {
+		return nil, err
+	}
+	tags := sessionTags(be)
+	prefix := sanitizeTmuxName(wc.Prefix) + "-"
+
+	sessions := []openSession{}
+	for _, n := range names {
+		if meta, ok := tags[n]; ok && meta.Workspace != "" {
+			if meta.Workspace == wc.Key || (wc.Name != "" && meta.Workspace == wc.Name) {
+				sessions = append(sessions, openSession{Name: n, Project: meta.Project})
+			}
+			continue
+		}
+
+		if wc.Prefix == "" || !strings.HasPrefix(n, prefix) {
+			continue
+		}
+		sessions = append(sessions, openSession{Name: n, Project: strings.TrimPrefix(n, prefix)})
+	}
+	return sessions, nil
+}
+
+// GetOpenProjectsByWorkspace returns the projects of wc that have an open
+// session.
+func GetOpenProjectsByWorkspace(wc config.WorkspaceConfig) []string {
+	sessions, err := workspaceSessions(GetBackend(wc), wc)
+	if err != nil {
+		log.Error("could not get sessions: %s\n", err.Error())
+		return []string{}
+	}
+
+	openProjects := []string{}
+	for _, s := range sessions {
+		openProjects = append(openProjects, s.Project)
+	}
+	return openProjects
+}
+
+// parseSessionTags parses "name\tworkspace\tproject" lines, skipping
+// sessions without a workspace tag.
+func parseSessionTags(out string) map[string]SessionMeta {
+	tags := map[string]SessionMeta{}
+	for _, line := range strings.Split(strings.TrimSpace(out), "\n") {
+		f := strings.Split(line, "\t")
+		if len(f) != 3 || f[1] == "" {
+			continue
+		}
+		tags[f[0]] = SessionMeta{Workspace: f[1], Project: f[2]}
+	}
+	return tags
+}
@@ -0,0 +1,108 @@
+package workspacer
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/JamesTiberiusKirk/workspacer/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetOpenProjectsByWorkspace(t *testing.T) {
+	// "wk-old" predates tagging; "wk-tools-x" is tagged for another workspace
+	// whose prefix happens to start with ours.
+	rec := useRecorder(t, "wk-old", "wkx-api", "personal-dots")
+	wc := testWorkspace(t)
+	require.NoError(t, rec.CreateSession(SessionSpec{Name: "wk-my.app", Meta: SessionMeta{Workspace: "work", Project: "my.app"}}))
+	require.NoError(t, rec.CreateSession(SessionSpec{Name: "wk-tools-x", Meta: SessionMeta{Workspace: "tools", Project: "x"}}))
+
+	assert.Equal(t, []string{"old", "my.app"}, GetOpenProjectsByWorkspace(wc))
+}
+
+func TestListWorkspaceSessions(t *testing.T) {
+	rec := useRecorder(t, "wk-web")
+	wc := testWorkspace(t)
+	wc.EnableCache = true
+	require.NoError(t, rec.CreateSession(SessionSpec{Name: "wk-api", Meta: SessionMeta{Workspace: "work", Project: "api"}}))
+	cache := LoadCache(wc)
+	cache.UpdateGitInfo("api", repoGitInfo{name: "api", branch: "main", changesCount: 2})
+	require.NoError(t, SaveCache(wc, cache))
+
+	initRepo(t, filepath.Join(wc.Path, "api"), 1)
+	wt, err := AddWorktree(wc, "api", "feature/x")
+	require.NoError(t, err)
+	writeFile(t, filepath.Join(wt.Path, "wip.txt"), "x")
+	require.NoError(t, rec.CreateSession(SessionSpec{Name: "wk-api@feature_x", Meta: SessionMeta{Workspace: "work", Project: "api@feature/x"}}))
+
+	sessions, err := ListWorkspaceSessions(wc)
+	require.NoError(t, err)
+	assert.Equal(t, []SessionStatus{
+		{Workspace: "work", Project: "web", Session: "wk-web"},
+		{Workspace: "work", Project: "api", Session: "wk-api", Branch: "main", Changes: 2},
+		{Workspace: "work", Project: "api@feature/x", Session: "wk-api@feature_x", Branch: "feature/x", Changes: 1},
+	}, sessions)
+
+	data, err := json.Marshal(sessions[0])
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"workspace":"work","project":"web","session":"wk-web","changes":0}`, string(data),
+		"details the backend doesn't report are left out")
+}
+
+func TestListWorkspaceSessionsWithoutServer(t *testing.T) {
+	// tmux exits 1 when no server is running.
+	bin := t.TempDir()
+	script := "#!/bin/sh\necho 'no server running on /tmp/tmux-0/default' >&2\nexit 1\n"
+	require.NoError(t, os.WriteFile(filepath.Join(bin, "tmux"), []byte(script), 0755))
+	t.Setenv("PATH", bin+string(os.PathListSeparator)+os.Getenv("PATH"))
+	t.Setenv("WORKSPACER_MUX", "")
+	wc := testWorkspace(t)
+	wc.Multiplexer = config.MuxTmux
+
+	sessions, err := ListWorkspaceSessions(wc)
+	require.NoError(t, err)
+	assert.Empty(t, sessions)
+
+	data, err := json.Marshal(sessions)
+	require.NoError(t, err)
+	assert.Equal(t, "[]", string(data))
+}
+
+func TestCurrentSessionMetaIgnoresMuxOverride(t *testing.T) {
+	dir := t.TempDir()
+	script := `#!/bin/sh
+case "$*" in
+list) echo "wk-api: 1 windows (attached)" ;;
+"run wk-api show-options -v @workspacer_workspace") echo work ;;
+"run wk-api show-options -v @workspacer_project") echo api ;;
+esac
+`
+	bin := filepath.Join(dir, "gtmux")
+	require.NoError(t, os.WriteFile(bin, []byte(script), 0755))
+	t.Setenv("GTMUX_BIN", bin)
+	t.Setenv("GTMUX", "/tmp/gtmux.sock,1,wk-api")
+	t.Setenv("WORKSPACER_MUX", "tmux")
+
+	name, meta, ok := CurrentSessionMeta()
+	require.True(t, ok)
+	assert.Equal(t, "wk-api", name)
+	assert.Equal(t, SessionMeta{Workspace: "work", Project: "api"}, meta, "tags come from gtmux, where the session is")
+}
+
+func TestWorkspaceSessionsByKey(t *testing.T) {
+	rec := useRecorder(t)
+	require.NoError(t, rec.CreateSession(SessionSpec{Name: "a-api", Meta: SessionMeta{Workspace: "alpha", Project: "api"}}))
+	require.NoError(t, rec.CreateSession(SessionSpec{Name: "b-web", Meta: SessionMeta{Workspace: "beta", Project: "web"}}))
+	require.NoError(t, rec.CreateSession(SessionSpec{Name: "a-old", Meta: SessionMeta{Workspace: "Alpha", Project: "old"}}))
+
+	// Neither workspace sets name:, so only their keys tell them apart.
+	alpha := config.WorkspaceConfig{Key: "alpha", Prefix: "a"}
+	beta := config.WorkspaceConfig{Key: "beta", Prefix: "b"}
+	assert.Equal(t, []string{"api"}, GetOpenProjectsByWorkspace(alpha))
+	assert.Equal(t, []string{"web"}, GetOpenProjectsByWorkspace(beta))
+
+	alpha.Name = "Alpha"
+	assert.Equal(t, []string{"api", "old"}, GetOpenProjectsByWorkspace(alpha), "sessions tagged with the name by older versions")
+}
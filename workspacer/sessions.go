@@ -0,0 +1,96 @@
+package workspacer
+
+import (
+	"sort"
+	"time"
+
+	"github.com/JamesTiberiusKirk/workspacer/config"
+)
+
+// SessionInfo is what a backend knows about a running session beyond its
+// name. Zero values (a nil Attached) mean the backend doesn't report that
+// detail.
+type SessionInfo struct {
+	Windows      int
+	Attached     *bool
+	LastActivity time.Time
+}
+
+// SessionDescriber is implemented by backends that can report window counts,
+// attached state and activity for their sessions, for `list`. Like
+// SessionCapturer it's optional; other backends list names only.
+type SessionDescriber interface {
+	DescribeSessions() (map[string]SessionInfo, error)
+}
+
+// SessionStatus is one open workspace session as shown by `list`. Windows,
+// Attached and LastActivity are left out when the backend doesn't report
+// them. Branch and Changes come from the workspace cache, so they're empty
+// unless the cache has git info for the project; a worktree's are read from
+// its checkout.
+type SessionStatus struct {
+	Workspace    string     `json:"workspace"`
+	Project      string     `json:"project"`
+	Session      string     `json:"session"`
+	Windows      int        `json:"windows,omitempty"`
+	Attached     *bool      `json:"attached,omitempty"`
+	LastActivity *time.Time `json:"last_activity,omitempty"`
+	Branch       string     `json:"branch,omitempty"`
+	Changes      int        `json:"changes"`
+}
+
+// ListWorkspaceSessions returns the open sessions of wc, most recently active
+// first.
+func ListWorkspaceSessions(wc config.WorkspaceConfig) ([]SessionStatus, error) {
+	be := GetBackend(wc)
+	sessions, err := workspaceSessions(be, wc)
+	if err != nil {
+		return nil, err
+	}
+	statuses := []SessionStatus{}
+	if len(sessions) == 0 {
+		return statuses, nil
+	}
+
+	info := map[string]SessionInfo{}
+	if d, ok := be.(SessionDescriber); ok {
+		if info, err = d.DescribeSessions(); err != nil {
+			return nil, err
+		}
+	}
+	cache := LoadCache(wc)
+
+	for _, s := range sessions {
+		i := info[s.Name]
+		status := SessionStatus{
+			Workspace: wc.Key,
+			Project:   s.Project,
+			Session:   s.Name,
+			Windows:   i.Windows,
+			Attached:  i.Attached,
+		}
+		if !i.LastActivity.IsZero() {
+			status.LastActivity = &i.LastActivity
+		}
+		if _, wt := SplitWorktree(s.Project); wt != "" {
+			// Worktrees aren't cached; reading one checkout is cheap.
+			if path, err := checkoutPath(wc, s.Project); err == nil {
+				if gs, err := readGitStatus(path); err == nil {
+					status.Branch = gs.branch
+					status.Changes = gs.changes
+				}
+			}
+		} else if pc, ok := cache.GetProjectCache(s.Project); ok {
+			status.Branch = pc.GitBranch
+			status.Changes = pc.GitChanges
+		}
+		statuses = append(statuses, status)
+	}
+
+	// Sessions without a known activity go last.
+	sort.SliceStable(statuses, func(i, j int) bool {
+		a, b := statuses[i].LastActivity, statuses[j].LastActivity
+		return a != nil && (b == nil || a.After(*b))
+	})
+	return statuses, nil
+}
@@ -0,0 +1,204 @@
+package workspacer
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"time"
+
+	"github.com/JamesTiberiusKirk/workspacer/config"
+	"github.com/JamesTiberiusKirk/workspacer/state"
+	"github.com/JamesTiberiusKirk/workspacer/util"
+)
+
+const (
+	snapshotsFileName  = ".workspacer-snapshots.json"
+	snapshotTimeFormat = "20060102-150405"
+	// maxSnapshots is how many snapshots a workspace keeps; taking another
+	// drops the oldest.
+	maxSnapshots = 20
+)
+
+// SessionCapturer is implemented by backends that can describe a running
+// session for snapshots. It's separate from SessionBackend because not every
+// multiplexer exposes pane cwds and commands.
+type SessionCapturer interface {
+	CaptureSession(name string) (SessionSnapshot, error)
+}
+
+// WorkspaceSnapshot is every workspace session at one point in time.
+type WorkspaceSnapshot struct {
+	Workspace string            `json:"workspace"`
+	CreatedAt time.Time         `json:"created_at"`
+	Sessions  []SessionSnapshot `json:"sessions"`
+}
+
+// SessionSnapshot is one captured session. Meta and Env are restored with it,
+// so a restored session is tagged and set up like the original.
+type SessionSnapshot struct {
+	Name    string            `json:"name"`
+	Path    string            `json:"path"`
+	Meta    SessionMeta       `json:"meta"`
+	Env     map[string]string `json:"env,omitempty"`
+	Windows []WindowSnapshot  `json:"windows"`
+}
+
+// WindowSnapshot is one captured window. Layout is whatever the backend
+// reports (for tmux the exact layout string, so geometry is restored too).
+type WindowSnapshot struct {
+	Name   string         `json:"name"`
+	Layout string         `json:"layout,omitempty"`
+	Panes  []PaneSnapshot `json:"panes"`
+}
+
+// PaneSnapshot is one captured pane. Command is empty for a bare shell.
+type PaneSnapshot struct {
+	Path    string `json:"path"`
+	Command string `json:"command,omitempty"`
+}
+
+// GetSnapshotsPath returns the path to a workspace's snapshot file, next to
+// its cache file. A single file (rather than a directory) keeps it out of the
+// project picker.
+func GetSnapshotsPath(wc config.WorkspaceConfig) string {
+	return filepath.Join(util.GetWorkspacePath(wc), snapshotsFileName)
+}
+
+// ID is the snapshot's timestamp, used to pick it for restore.
+func (s WorkspaceSnapshot) ID() string {
+	return s.CreatedAt.Format(snapshotTimeFormat)
+}
+
+// TakeSnapshot captures every open session of the workspace and adds it to
+// the workspace's snapshot file, keeping the newest maxSnapshots, and returns
+// the snapshot's id. IDs have one-second resolution, so a snapshot taken in
+// the same second as another replaces it. With --dry-run nothing is written.
+func TakeSnapshot(wc config.WorkspaceConfig) (string, error) {
+	be := GetBackend(wc)
+	capturer, ok := be.(SessionCapturer)
+	if !ok {
+		return "", fmt.Errorf("this multiplexer does not support snapshots (tmux only)")
+	}
+
+	sessions, err := workspaceSessions(be, wc)
+	if err != nil {
+		return "", fmt.Errorf("failed to list sessions: %w", err)
+	}
+
+	snap := WorkspaceSnapshot{Workspace: wc.Name, CreatedAt: time.Now()}
+	for _, open := range sessions {
+		s, err := capturer.CaptureSession(open.Name)
+		if err != nil {
+			return "", fmt.Errorf("failed to capture session %s: %w", open.Name, err)
+		}
+		s.Meta = SessionMeta{Workspace: wc.Key, Project: open.Project}
+		snap.Sessions = append(snap.Sessions, s)
+	}
+
+	if len(snap.Sessions) == 0 {
+		return "", fmt.Errorf("no open sessions in workspace %s", wc.Name)
+	}
+
+	snaps, err := ListSnapshots(wc)
+	if err != nil {
+		return "", err
+	}
+	snaps = slices.DeleteFunc(snaps, func(s WorkspaceSnapshot) bool { return s.ID() == snap.ID() })
+	snaps = append([]WorkspaceSnapshot{snap}, snaps...)
+	if len(snaps) > maxSnapshots {
+		snaps = snaps[:maxSnapshots]
+	}
+
+	if state.DryRun {
+		fmt.Printf("[dry-run] save snapshot %s of %d session(s) to %s\n", snap.ID(), len(snap.Sessions), GetSnapshotsPath(wc))
+		return snap.ID(), nil
+	}
+
+	data, err := json.MarshalIndent(snaps, "", "\t")
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal snapshots: %w", err)
+	}
+	if err := os.WriteFile(GetSnapshotsPath(wc), data, 0644); err != nil {
+		return "", fmt.Errorf("failed to write snapshot file: %w", err)
+	}
+
+	return snap.ID(), nil
+}
+
+// ListSnapshots returns the workspace's snapshots, newest first.
+func ListSnapshots(wc config.WorkspaceConfig) ([]WorkspaceSnapshot, error) {
+	data, err := os.ReadFile(GetSnapshotsPath(wc))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return []WorkspaceSnapshot{}, nil
+		}
+		return nil, fmt.Errorf("failed to read snapshot file: %w", err)
+	}
+
+	var snaps []WorkspaceSnapshot
+	if err := json.Unmarshal(data, &snaps); err != nil {
+		return nil, fmt.Errorf("failed to parse snapshot file: %w", err)
+	}
+
+	sort.Slice(snaps, func(i, j int) bool {
+		return snaps[i].CreatedAt.After(snaps[j].CreatedAt)
+	})
+	return snaps, nil
+}
+
+// LoadSnapshot returns the snapshot with the given id. An empty id returns the
+// newest one.
+func LoadSnapshot(wc config.WorkspaceConfig, id string) (*WorkspaceSnapshot, error) {
+	snaps, err := ListSnapshots(wc)
+	if err != nil {
+		return nil, err
+	}
+	if len(snaps) == 0 {
+		return nil, fmt.Errorf("no snapshots for workspace %s", wc.Name)
+	}
+	if id == "" {
+		return &snaps[0], nil
+	}
+
+	for i := range snaps {
+		if snaps[i].ID() == id {
+			return &snaps[i], nil
+		}
+	}
+	return nil, fmt.Errorf("snapshot %s not found", id)
+}
+
+// RestoreSnapshot rebuilds every session in snap that isn't already open,
+// detached. It returns the names of the sessions it created.
+func RestoreSnapshot(wc config.WorkspaceConfig, snap *WorkspaceSnapshot) ([]string, error) {
+	be := GetBackend(wc)
+
+	restored := []string{}
+	for _, s := range snap.Sessions {
+		if be.HasSession(s.Name) {
+			continue
+		}
+		if err := be.CreateSession(s.spec()); err != nil {
+			return restored, fmt.Errorf("failed to restore session %s: %w", s.Name, err)
+		}
+		restored = append(restored, s.Name)
+	}
+	return restored, nil
+}
+
+// spec turns a captured session back into something a backend can build.
+// The captured layout puts the panes back where they were.
+func (s SessionSnapshot) spec() SessionSpec {
+	spec := SessionSpec{Name: s.Name, Path: s.Path, Env: s.Env, Meta: s.Meta}
+	for _, w := range s.Windows {
+		ws := WindowSpec{Name: w.Name, Layout: w.Layout}
+		for _, p := range w.Panes {
+			ws.Panes = append(ws.Panes, PaneSpec{Command: p.Command, Path: p.Path})
+		}
+		spec.Windows = append(spec.Windows, ws)
+	}
+	return spec
+}
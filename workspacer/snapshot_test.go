@@ -0,0 +1,221 @@
+package workspacer
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/JamesTiberiusKirk/workspacer/state"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// capturingBackend stands in for tmux: its sessions exist, can be captured
+// and may be tagged, but nothing can be created.
+type capturingBackend struct {
+	sessions []SessionSnapshot
+	tags     map[string]SessionMeta
+}
+
+func (b *capturingBackend) SessionTags() (map[string]SessionMeta, error) {
+	return b.tags, nil
+}
+
+func (b *capturingBackend) HasSession(name string) bool {
+	_, err := b.CaptureSession(name)
+	return err == nil
+}
+
+func (b *capturingBackend) ListSessions() ([]string, error) {
+	names := []string{}
+	for _, s := range b.sessions {
+		names = append(names, s.Name)
+	}
+	return names, nil
+}
+
+func (b *capturingBackend) KillSession(string) error        { return nil }
+func (b *capturingBackend) CreateSession(SessionSpec) error { return nil }
+func (b *capturingBackend) Attach(string) error             { return nil }
+
+func (b *capturingBackend) CaptureSession(name string) (SessionSnapshot, error) {
+	for _, s := range b.sessions {
+		if s.Name == name {
+			return s, nil
+		}
+	}
+	return SessionSnapshot{}, fmt.Errorf("no session %s", name)
+}
+
+// useCapturer routes backend calls through a recorder over sessions.
+func useCapturer(t *testing.T, tags map[string]SessionMeta, sessions ...SessionSnapshot) *RecordingBackend {
+	t.Helper()
+	rec := NewRecordingBackend(&capturingBackend{sessions: sessions, tags: tags}, nil)
+	backendOverride = rec
+	t.Cleanup(func() { backendOverride = nil })
+	return rec
+}
+
+var apiSnapshot = SessionSnapshot{
+	Name: "wk-api",
+	Path: "/src/api",
+	Meta: SessionMeta{Workspace: "work", Project: "api"},
+	Env:  map[string]string{"WORKSPACER_PROJECT": "api", "STAGE": "dev"},
+	Windows: []WindowSnapshot{
+		{Name: "api", Layout: "b25d,159x48,0,0{79x48,0,0,0,79x48,80,0,1}", Panes: []PaneSnapshot{
+			{Path: "/src/api", Command: "nvim ."},
+			{Path: "/src/api/cmd"},
+		}},
+		{Name: "server", Panes: []PaneSnapshot{{Path: "/src/api", Command: "go run ."}}},
+	},
+}
+
+func TestSnapshotSpec(t *testing.T) {
+	assert.Equal(t, SessionSpec{
+		Name: "wk-api",
+		Path: "/src/api",
+		Env:  map[string]string{"WORKSPACER_PROJECT": "api", "STAGE": "dev"},
+		Meta: SessionMeta{Workspace: "work", Project: "api"},
+		Windows: []WindowSpec{
+			{Name: "api", Layout: "b25d,159x48,0,0{79x48,0,0,0,79x48,80,0,1}", Panes: []PaneSpec{
+				{Path: "/src/api", Command: "nvim ."},
+				{Path: "/src/api/cmd"},
+			}},
+			{Name: "server", Panes: []PaneSpec{{Path: "/src/api", Command: "go run ."}}},
+		},
+	}, apiSnapshot.spec())
+}
+
+func TestTakeSnapshot(t *testing.T) {
+	t.Run("Captures_workspace_sessions", func(t *testing.T) {
+		wc := testWorkspace(t)
+		untagged := apiSnapshot
+		untagged.Meta = SessionMeta{}
+		docs := SessionSnapshot{Name: "notes-docs", Path: "/src/docs"}
+		useCapturer(t, map[string]SessionMeta{
+			"notes-docs": {Workspace: "work", Project: "docs"},
+			"wk-api-web": {Workspace: "wk-api", Project: "web"},
+		}, untagged, docs, SessionSnapshot{Name: "wk-api-web"}, SessionSnapshot{Name: "home-notes"})
+
+		id, err := TakeSnapshot(wc)
+		require.NoError(t, err)
+
+		snap, err := LoadSnapshot(wc, "")
+		require.NoError(t, err)
+		assert.Equal(t, id, snap.ID())
+		assert.Equal(t, "work", snap.Workspace)
+		docs.Meta = SessionMeta{Workspace: "work", Project: "docs"}
+		assert.Equal(t, []SessionSnapshot{apiSnapshot, docs}, snap.Sessions,
+			"sessions are picked by tag, else by prefix, and all come back tagged")
+	})
+
+	t.Run("Nothing_to_capture", func(t *testing.T) {
+		wc := testWorkspace(t)
+		useCapturer(t, nil, SessionSnapshot{Name: "home-notes"})
+
+		_, err := TakeSnapshot(wc)
+		assert.EqualError(t, err, "no open sessions in workspace work")
+		_, err = os.Stat(GetSnapshotsPath(wc))
+		assert.True(t, os.IsNotExist(err))
+	})
+
+	t.Run("Same_second_replaces", func(t *testing.T) {
+		wc := testWorkspace(t)
+		useCapturer(t, nil, apiSnapshot)
+
+		_, err := TakeSnapshot(wc)
+		require.NoError(t, err)
+		id, err := TakeSnapshot(wc)
+		require.NoError(t, err)
+
+		snaps, err := ListSnapshots(wc)
+		require.NoError(t, err)
+		assert.Equal(t, id, snaps[0].ID())
+		ids := map[string]bool{}
+		for _, s := range snaps {
+			assert.False(t, ids[s.ID()], "duplicate snapshot id %s", s.ID())
+			ids[s.ID()] = true
+		}
+	})
+
+	t.Run("Dry_run", func(t *testing.T) {
+		wc := testWorkspace(t)
+		useCapturer(t, nil, apiSnapshot)
+		state.DryRun = true
+		t.Cleanup(func() { state.DryRun = false })
+
+		_, err := TakeSnapshot(wc)
+		require.NoError(t, err)
+		_, err = os.Stat(GetSnapshotsPath(wc))
+		assert.True(t, os.IsNotExist(err), "nothing is written")
+	})
+
+	t.Run("Keeps_the_newest", func(t *testing.T) {
+		wc := testWorkspace(t)
+		useCapturer(t, nil, apiSnapshot)
+		old := []WorkspaceSnapshot{}
+		for i := range maxSnapshots {
+			old = append(old, WorkspaceSnapshot{
+				Workspace: "work",
+				CreatedAt: time.Date(2024, 1, 1, 0, i, 0, 0, time.UTC),
+				Sessions:  []SessionSnapshot{apiSnapshot},
+			})
+		}
+		data, err := json.Marshal(old)
+		require.NoError(t, err)
+		require.NoError(t, os.WriteFile(GetSnapshotsPath(wc), data, 0644))
+
+		id, err := TakeSnapshot(wc)
+		require.NoError(t, err)
+
+		snaps, err := ListSnapshots(wc)
+		require.NoError(t, err)
+		require.Len(t, snaps, maxSnapshots)
+		assert.Equal(t, id, snaps[0].ID())
+		assert.Equal(t, "20240101-000100", snaps[len(snaps)-1].ID(), "the oldest is dropped")
+	})
+}
+
+func TestLoadSnapshot(t *testing.T) {
+	wc := testWorkspace(t)
+
+	_, err := LoadSnapshot(wc, "")
+	assert.EqualError(t, err, "no snapshots for workspace work")
+
+	snaps := []WorkspaceSnapshot{
+		{Workspace: "work", CreatedAt: time.Date(2025, 1, 1, 9, 30, 0, 0, time.UTC)},
+		{Workspace: "work", CreatedAt: time.Date(2025, 1, 2, 9, 30, 0, 0, time.UTC)},
+	}
+	data, err := json.Marshal(snaps)
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(GetSnapshotsPath(wc), data, 0644))
+
+	snap, err := LoadSnapshot(wc, "")
+	require.NoError(t, err)
+	assert.Equal(t, "20250102-093000", snap.ID(), "the newest by default")
+
+	snap, err = LoadSnapshot(wc, "20250101-093000")
+	require.NoError(t, err)
+	assert.Equal(t, "20250101-093000", snap.ID())
+
+	_, err = LoadSnapshot(wc, "20990101-000000")
+	assert.EqualError(t, err, "snapshot 20990101-000000 not found")
+}
+
+func TestRestoreSnapshot(t *testing.T) {
+	wc := testWorkspace(t)
+	rec := useRecorder(t, "wk-web")
+	snap := &WorkspaceSnapshot{Workspace: "work", Sessions: []SessionSnapshot{
+		apiSnapshot,
+		{Name: "wk-web", Path: "/src/web"},
+	}}
+
+	restored, err := RestoreSnapshot(wc, snap)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"wk-api"}, restored, "open sessions are left alone")
+	assert.Equal(t, []SessionSpec{apiSnapshot.spec()}, rec.Specs)
+	assert.Equal(t, []RecordedCall{{Op: "create", Name: "wk-api"}}, rec.Calls, "restored sessions stay detached")
+	assert.Equal(t, apiSnapshot.Meta, sessionTags(rec)["wk-api"], "restored sessions are tagged")
+}
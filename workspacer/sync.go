@@ -0,0 +1,232 @@
+package workspacer
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/JamesTiberiusKirk/workspacer/config"
+	"github.com/JamesTiberiusKirk/workspacer/log"
+	"github.com/JamesTiberiusKirk/workspacer/util"
+)
+
+// SyncStatus is what sync did with a project.
+type SyncStatus string
+
+const (
+	SyncUpdated    SyncStatus = "updated"    // fast-forwarded
+	SyncUpToDate   SyncStatus = "up-to-date" // nothing to pull
+	SyncSkipped    SyncStatus = "skipped"    // dirty, not on the default branch, detached or no upstream
+	SyncConflicted SyncStatus = "conflicted" // diverged from upstream, can't fast-forward
+	SyncFailed     SyncStatus = "failed"     // git failed, e.g. fetch couldn't reach the remote
+)
+
+// SyncOptions loosens which projects sync pulls.
+type SyncOptions struct {
+	Dirty       bool // pull trees with uncommitted changes; git still refuses to overwrite them
+	AllBranches bool // pull whatever branch is checked out, not just the default one
+	DryRun      bool // fetch, but report what would be pulled instead of pulling
+}
+
+// SyncResult is the outcome of syncing one project.
+type SyncResult struct {
+	Project string
+	Branch  string
+	Status  SyncStatus
+	Detail  string
+}
+
+// GitProjects returns every git repo in the workspace folder, sister repos
+// included, sorted by name.
+func GitProjects(wc config.WorkspaceConfig) ([]string, error) {
+	entries, err := os.ReadDir(util.GetWorkspacePath(wc))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read workspace directory: %w", err)
+	}
+
+	projects := []string{}
+	for _, e := range entries {
+		if e.IsDir() && e.Name() != worktreeDir && util.HasGitSubfolder(filepath.Join(util.GetWorkspacePath(wc), e.Name())) {
+			projects = append(projects, e.Name())
+		}
+	}
+	return projects, nil
+}
+
+// SyncWorkspace fetches every git project of the workspace and fast-forwards
+// it to its upstream, at most workers at a time. onDone, when set, is called
+// (from one goroutine at a time) as each project finishes. Results are in the
+// order of GitProjects. The cache's git info is refreshed afterwards.
+func SyncWorkspace(wc config.WorkspaceConfig, opts SyncOptions, workers int, onDone func(done, total int, r SyncResult)) ([]SyncResult, error) {
+	projects, err := GitProjects(wc)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]SyncResult, len(projects))
+	var (
+		mu   sync.Mutex
+		done int
+	)
+	parallel(len(projects), workers, func(i int) {
+		r := syncProject(wc, projects[i], opts)
+
+		mu.Lock()
+		results[i] = r
+		done++
+		if onDone != nil {
+			onDone(done, len(projects), r)
+		}
+		mu.Unlock()
+	})
+
+	if !opts.DryRun {
+		if err := refreshGitCache(wc); err != nil {
+			log.Error("Failed to refresh cache: %s", err.Error())
+		}
+	}
+	return results, nil
+}
+
+func syncProject(wc config.WorkspaceConfig, project string, opts SyncOptions) SyncResult {
+	path := filepath.Join(util.GetWorkspacePath(wc), project)
+	r := SyncResult{Project: project}
+	result := func(status SyncStatus, format string, args ...any) SyncResult {
+		r.Status, r.Detail = status, fmt.Sprintf(format, args...)
+		return r
+	}
+
+	st, err := readGitStatus(path)
+	if err != nil {
+		return result(SyncFailed, "%s", err.Error())
+	}
+	r.Branch = st.branch
+	if st.detached {
+		return result(SyncSkipped, "detached HEAD")
+	}
+	if st.changes > 0 && !opts.Dirty {
+		return result(SyncSkipped, "%s", plural(st.changes, "uncommitted change"))
+	}
+
+	if !opts.AllBranches && st.hasUpstream {
+		def, err := defaultBranch(path)
+		if err != nil {
+			return result(SyncSkipped, "default branch unknown, run git remote set-head origin --auto")
+		}
+		if def != r.Branch {
+			return result(SyncSkipped, "not on %s", def)
+		}
+	}
+
+	if _, err := util.ExecCmd(path, "git", "fetch", "--quiet", "--prune"); err != nil {
+		return result(SyncFailed, "fetch: %s", gitError(err))
+	}
+
+	ahead, behind, err := aheadBehind(path)
+	if errors.Is(err, errNoUpstream) {
+		return result(SyncSkipped, "no upstream")
+	} else if err != nil {
+		return result(SyncFailed, "%s", err.Error())
+	}
+	switch {
+	case behind == 0:
+		return result(SyncUpToDate, "")
+	case ahead > 0:
+		return result(SyncConflicted, "diverged: %d ahead, %d behind", ahead, behind)
+	case opts.DryRun:
+		return result(SyncUpdated, "would pull %s", plural(behind, "commit"))
+	}
+
+	if _, err := util.ExecCmd(path, "git", "merge", "--ff-only", "--quiet", "@{upstream}"); err != nil {
+		return result(SyncFailed, "pull: %s", gitError(err))
+	}
+	return result(SyncUpdated, "pulled %s", plural(behind, "commit"))
+}
+
+func plural(n int, word string) string {
+	if n == 1 {
+		return "1 " + word
+	}
+	return fmt.Sprintf("%d %ss", n, word)
+}
+
+// defaultBranch is the branch origin/HEAD points at. git resolves it, so
+// however the ref is stored it's found; it's an error when origin/HEAD isn't
+// set.
+func defaultBranch(path string) (string, error) {
+	ref, err := util.ExecCmd(path, "git", "symbolic-ref", "--quiet", "refs/remotes/origin/HEAD")
+	if err != nil {
+		return "", fmt.Errorf("origin/HEAD: %s", gitError(err))
+	}
+	branch, ok := strings.CutPrefix(ref, "refs/remotes/origin/")
+	if !ok {
+		return "", fmt.Errorf("origin/HEAD points at %s", ref)
+	}
+	return branch, nil
+}
+
+// gitError is the first line git printed for a failed util.ExecCmd, else
+// the error itself.
+func gitError(err error) string {
+	lines := strings.Split(strings.TrimSpace(err.Error()), "\n")
+	for _, l := range lines[1:] {
+		if l = strings.TrimSpace(l); l != "" {
+			return l
+		}
+	}
+	return lines[0]
+}
+
+// refreshGitCache reloads the cached git info of every project the picker
+// shows.
+func refreshGitCache(wc config.WorkspaceConfig) error {
+	if !wc.EnableCache || !wc.EnableGitInfo {
+		return nil
+	}
+	projects, err := GitProjects(wc)
+	if err != nil {
+		return err
+	}
+
+	repos := []string{}
+	for _, p := range projects {
+		if !util.IsSisterRepo(wc, p) {
+			repos = append(repos, p)
+		}
+	}
+	infos := loadGitInfo(wc, repos)
+
+	cache := LoadCache(wc)
+	for _, info := range infos {
+		cache.UpdateGitInfo(info.name, info)
+	}
+	return SaveCache(wc, cache)
+}
+
+// parallel calls fn with 0..n-1, at most workers at a time.
+func parallel(n, workers int, fn func(i int)) {
+	if workers < 1 {
+		workers = 1
+	}
+
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				fn(i)
+			}
+		}()
+	}
+
+	for i := 0; i < n; i++ {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+}
@@ -0,0 +1,99 @@
+package workspacer
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSyncWorkspace(t *testing.T) {
+	remotes := t.TempDir()
+	wc := testWorkspace(t, "notes")
+	wc.EnableCache, wc.EnableGitInfo = true, true
+
+	// clone makes a project cloned from its own remote, then runs setup.
+	clone := func(name string, setup func(remote, local string)) {
+		remote := filepath.Join(remotes, name)
+		initRepo(t, remote, 1)
+		local := filepath.Join(wc.Path, name)
+		gitRun(t, wc.Path, "clone", "--quiet", remote, local)
+		setup(remote, local)
+	}
+	commit := func(dir string) { gitRun(t, dir, "commit", "--quiet", "--allow-empty", "-m", "more in "+dir) }
+
+	clone("behind", func(remote, _ string) { commit(remote); commit(remote) })
+	clone("current", func(_, _ string) {})
+	clone("dirty", func(remote, local string) {
+		commit(remote)
+		require.NoError(t, os.WriteFile(filepath.Join(local, "wip.txt"), nil, 0644))
+	})
+	clone("feature", func(remote, local string) {
+		commit(remote)
+		gitRun(t, local, "checkout", "--quiet", "-b", "feature")
+	})
+	clone("diverged", func(remote, local string) { commit(remote); commit(local) })
+	clone("gone", func(remote, _ string) { require.NoError(t, os.RemoveAll(remote)) })
+	clone("headless", func(remote, local string) {
+		commit(remote)
+		gitRun(t, local, "remote", "set-head", "origin", "--delete")
+	})
+	initRepo(t, filepath.Join(wc.Path, "local"), 1)
+
+	statuses := func(results []SyncResult) map[string]SyncStatus {
+		m := map[string]SyncStatus{}
+		for _, r := range results {
+			m[r.Project] = r.Status
+		}
+		return m
+	}
+
+	t.Run("Dry_run_pulls_nothing", func(t *testing.T) {
+		results, err := SyncWorkspace(wc, SyncOptions{DryRun: true}, 4, nil)
+		require.NoError(t, err)
+		assert.Equal(t, SyncUpdated, statuses(results)["behind"])
+		assert.Equal(t, "2", gitRun(t, filepath.Join(wc.Path, "behind"), "rev-list", "--count", "HEAD..@{upstream}"))
+	})
+
+	t.Run("Fast_forwards_clean_default_branches", func(t *testing.T) {
+		var progress []int
+		results, err := SyncWorkspace(wc, SyncOptions{}, 4, func(done, total int, r SyncResult) {
+			progress = append(progress, done)
+			assert.Equal(t, 8, total)
+		})
+		require.NoError(t, err)
+
+		assert.Equal(t, map[string]SyncStatus{
+			"behind":   SyncUpdated,
+			"current":  SyncUpToDate,
+			"dirty":    SyncSkipped,
+			"feature":  SyncSkipped,
+			"diverged": SyncConflicted,
+			"gone":     SyncFailed,
+			"headless": SyncSkipped,
+			"local":    SyncSkipped,
+		}, statuses(results))
+		assert.Equal(t, []int{1, 2, 3, 4, 5, 6, 7, 8}, progress)
+		assert.Equal(t, SyncResult{Project: "behind", Branch: "main", Status: SyncUpdated, Detail: "pulled 2 commits"}, results[0])
+		assert.Equal(t, "0", gitRun(t, filepath.Join(wc.Path, "behind"), "rev-list", "--count", "HEAD..@{upstream}"))
+		for _, r := range results {
+			if r.Project == "headless" {
+				assert.Equal(t, "default branch unknown, run git remote set-head origin --auto", r.Detail)
+			}
+		}
+
+		cached, ok := LoadCache(wc).GetProjectCache("behind")
+		require.True(t, ok)
+		assert.Equal(t, "main", cached.GitBranch)
+	})
+
+	t.Run("Options_include_dirty_and_feature_branches", func(t *testing.T) {
+		results, err := SyncWorkspace(wc, SyncOptions{Dirty: true, AllBranches: true}, 4, nil)
+		require.NoError(t, err)
+		got := statuses(results)
+		assert.Equal(t, SyncUpdated, got["dirty"])
+		assert.Equal(t, SyncSkipped, got["feature"]) // no upstream for the new branch
+	})
+}
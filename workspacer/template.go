@@ -0,0 +1,97 @@
+package workspacer
+
+import (
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
+
+	"github.com/JamesTiberiusKirk/workspacer/config"
+	"github.com/JamesTiberiusKirk/workspacer/util"
+)
+
+// templateData is what pane commands, pane and window paths and window names
+// can refer to as Go templates, e.g. `nvim {{.File}}` or
+// `go run ./cmd/{{.Project}}`.
+type templateData struct {
+	Workspace string
+	Project   string // without the @branch of a worktree
+	Path      string // the session's directory, or a sister repo window's
+	Branch    string // checked out in Path, empty when it isn't a repo
+	File      string // from the `project:file:extra` target syntax
+	Extra     string
+	Env       map[string]string // the process env overlaid with the session's
+	Sisters   map[string]string // sister repo paths by name and by label
+}
+
+// newTemplateData collects the template values of a session rooted at path.
+func newTemplateData(workspace, project, path string, env map[string]string) templateData {
+	d := templateData{
+		Workspace: workspace,
+		Project:   project,
+		Path:      path,
+		Env:       map[string]string{},
+		Sisters:   map[string]string{},
+	}
+	if s, err := readGitStatus(path); err == nil {
+		d.Branch = s.branch
+	}
+	for _, kv := range os.Environ() {
+		if k, v, ok := strings.Cut(kv, "="); ok {
+			d.Env[k] = v
+		}
+	}
+	maps.Copy(d.Env, env)
+	return d
+}
+
+// addSisters makes the paths of the sister repos that exist available to
+// templates, by label too unless that's another repo's name.
+func (d templateData) addSisters(wc config.WorkspaceConfig, sisters []config.SisterRepoConfig) {
+	for _, sr := range sisters {
+		if util.DoesProjectExist(wc, sr.Name) {
+			d.Sisters[sr.Name] = filepath.Join(util.GetWorkspacePath(wc), sr.Name)
+		}
+	}
+	for _, sr := range sisters {
+		if _, taken := d.Sisters[sr.Label]; !taken && sr.Label != "" && d.Sisters[sr.Name] != "" {
+			d.Sisters[sr.Label] = d.Sisters[sr.Name]
+		}
+	}
+}
+
+// expand executes s as a template. Strings without {{ are returned as they
+// are; a template that fails is reported and used literally.
+func (d templateData) expand(what, s string) string {
+	if !strings.Contains(s, "{{") {
+		return s
+	}
+	t, err := template.New(what).Option("missingkey=zero").Parse(s)
+	if err != nil {
+		fmt.Printf("Ignoring invalid template in %s %q: %s\n", what, s, err)
+		return s
+	}
+	var b strings.Builder
+	if err := t.Execute(&b, d); err != nil {
+		fmt.Printf("Ignoring invalid template in %s %q: %s\n", what, s, err)
+		return s
+	}
+	return b.String()
+}
+
+// window expands the templates in a window's name and path and in its panes'
+// commands and paths.
+func (d templateData) window(w config.WindowConfig) config.WindowConfig {
+	w.Name = d.expand("window name", w.Name)
+	w.Path = d.expand("window path", w.Path)
+	panes := make([]config.PanesConfig, len(w.Panes))
+	for i, p := range w.Panes {
+		p.Command = d.expand("pane command", p.Command)
+		p.Path = d.expand("pane path", p.Path)
+		panes[i] = p
+	}
+	w.Panes = panes
+	return w
+}
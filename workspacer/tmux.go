@@ -4,19 +4,27 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strconv"
 	"strings"
 
 	"github.com/JamesTiberiusKirk/workspacer/config"
 	"github.com/JamesTiberiusKirk/workspacer/util"
 )
 
+// sessionNameReplacer swaps out characters multiplexers disallow in session
+// names: tmux treats "." and ":" as target separators, and zellij names its
+// layout file after the session, so a "/" (as in a worktree's
+// feature/login branch) would point into a missing directory.
+var sessionNameReplacer = strings.NewReplacer(".", "_", ":", "_", "/", "_")
+
 // sanitizeTmuxName replaces characters multiplexers disallow in session names.
 func sanitizeTmuxName(name string) string {
-	return strings.ReplaceAll(name, ".", "_")
+	return sessionNameReplacer.Replace(name)
 }
 
-// applyVimArgs appends the project's file/extra-command options to a vim-family
-// pane command (from the `project:file:extra` target syntax).
+// applyVimArgs appends the project's file/extra-command options to a bare
+// vim-family pane command (from the `project:file:extra` target syntax).
+// Other editors can use {{.File}} and {{.Extra}} in their command instead.
 func applyVimArgs(cmd, fileOption, extraVimCommands string) string {
 	switch cmd {
 	case "vi", "vim", "nvim":
@@ -30,12 +38,70 @@ func applyVimArgs(cmd, fileOption, extraVimCommands string) string {
 	return cmd
 }
 
+// paneSpec converts a preset pane running command. Its path is resolved
+// against dir, the window's start-dir: ~ is the home dir, relative paths are
+// under dir. The legacy size (width percent) is overridden by width. Invalid
+// orientations and sizes are reported and dropped.
+func paneSpec(p config.PanesConfig, command, dir string) PaneSpec {
+	ps := PaneSpec{Command: command}
+
+	if p.Size > 0 && p.Size < 100 {
+		ps.Width = fmt.Sprintf("%d%%", p.Size)
+	}
+	if p.Width != "" {
+		if isPaneSize(p.Width) {
+			ps.Width = p.Width
+		} else {
+			fmt.Printf("Ignoring invalid pane width %q (want e.g. 60%% or 120)\n", p.Width)
+		}
+	}
+	if p.Height != "" {
+		if isPaneSize(p.Height) {
+			ps.Height = p.Height
+		} else {
+			fmt.Printf("Ignoring invalid pane height %q (want e.g. 30%% or 15)\n", p.Height)
+		}
+	}
+
+	if p.Orientation != "" {
+		if p.Orientation.IsValid() {
+			ps.Orientation = p.Orientation
+		} else {
+			fmt.Printf("Ignoring invalid pane orientation %q (want horizontal or vertical)\n", p.Orientation)
+		}
+	}
+
+	if p.Path != "" {
+		path, err := util.ExpandTilde(p.Path)
+		if err != nil {
+			fmt.Printf("Error expanding path %s: %s\n", p.Path, err)
+			return ps
+		}
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(dir, path)
+		}
+		ps.Path = path
+	}
+	return ps
+}
+
+// isPaneSize reports whether s is a percentage between 1% and 99% or a
+// positive number of cells.
+func isPaneSize(s string) bool {
+	if pct, ok := strings.CutSuffix(s, "%"); ok {
+		n, err := strconv.Atoi(pct)
+		return err == nil && n > 0 && n < 100
+	}
+	n, err := strconv.Atoi(s)
+	return err == nil && n > 0
+}
+
 // StartOrSwitchToTmpSession creates (or attaches/switches to) a session named
-// after the given path and rooted in it. No workspace or preset — always tmux
-// (there's no workspace config to select a backend from).
-func StartOrSwitchToTmpSession(path string) {
+// after the given path and rooted in it. No workspace or preset, so the backend
+// comes from mux (the global default_multiplexer).
+func StartOrSwitchToTmpSession(path string, mux config.MuxBackend) {
 	name := sanitizeTmuxName(filepath.Base(path))
-	be := GetBackend()
+	be := GetBackendByName(mux)
 
 	if !be.HasSession(name) {
 		spec := SessionSpec{Name: name, Path: path, Windows: []WindowSpec{{Panes: []PaneSpec{{}}}}}
@@ -49,47 +115,172 @@ func StartOrSwitchToTmpSession(path string) {
 	}
 }
 
-func CloseAllSessionsInWorkspace(wc config.WorkspaceConfig) {
+// CloseAllSessionsInWorkspace kills every session of the workspace, then runs
+// the post_kill hooks of the workspace, the project's preset and its manifest
+// (when already trusted) for each.
+func CloseAllSessionsInWorkspace(wc config.WorkspaceConfig, presets map[string]config.SessionConfig) {
 	if wc.Prefix == "" {
 		fmt.Println("prefix is empty")
 		return
 	}
-	be := GetBackend()
-	names, err := be.ListSessions()
+	be := GetBackend(wc)
+	sessions, err := workspaceSessions(be, wc)
 	if err != nil {
 		fmt.Println("error ", err.Error())
 		return
 	}
-	for _, n := range names {
-		if strings.HasPrefix(n, wc.Prefix) {
-			if err := be.KillSession(n); err != nil {
-				fmt.Println("error ", err.Error())
-			}
+	for _, s := range sessions {
+		if err := be.KillSession(s.Name); err != nil {
+			fmt.Println("error ", err.Error())
+			continue
+		}
+		sc, _ := withManifest(wc, presets, s.Project, projectSessionConfig(wc, presets, s.Project), nil, false)
+		hooks := mergeHooks(wc.Hooks, sc.Hooks)
+
+		path, err := targetPath(wc, s.Project)
+		if err != nil {
+			path = util.GetWorkspacePath(wc)
 		}
+		reportHooks(hooks, HookPostKill, hookTarget{Workspace: wc.Name, Project: s.Project, Session: s.Name, Path: path})
 	}
 }
 
-// StartOrSwitchToTmuxPreset builds (or attaches to) a session from a standalone
-// preset rooted at basePath. No workspace config → always tmux.
-func StartOrSwitchToTmuxPreset(name string, basePath string, preset config.SessionConfig) {
-	name = sanitizeTmuxName(name)
-	be := GetBackend()
+// targetPath is the folder a session target opens in: the workspace root for
+// "root", the worktree for "project@branch", else the project folder. A
+// project's sub_path, e.g. services/api in a monorepo, is appended when it
+// exists.
+func targetPath(wc config.WorkspaceConfig, target string) (string, error) {
+	path, err := checkoutPath(wc, target)
+	if err != nil || target == "root" {
+		return path, err
+	}
 
-	if be.HasSession(name) {
-		if err := be.Attach(name); err != nil {
-			fmt.Println("error ", err.Error())
+	project, _ := SplitWorktree(target)
+	pc, _ := util.GetProjectConfig(wc, project)
+	if pc.SubPath == "" {
+		return path, nil
+	}
+	sub := filepath.Join(path, pc.SubPath)
+	if fi, err := os.Stat(sub); err != nil || !fi.IsDir() {
+		fmt.Printf("Sub path %s not found in %s, using the project root\n", pc.SubPath, path)
+		return path, nil
+	}
+	return sub, nil
+}
+
+// checkoutPath is the root of a session target's checkout: the workspace root
+// for "root", the worktree for "project@branch", else the project folder.
+func checkoutPath(wc config.WorkspaceConfig, target string) (string, error) {
+	if target == "root" {
+		return util.GetWorkspacePath(wc), nil
+	}
+	project, worktree := SplitWorktree(target)
+	if worktree == "" {
+		return filepath.Join(util.GetWorkspacePath(wc), project), nil
+	}
+	wt, err := FindWorktree(wc, project, worktree)
+	if err != nil {
+		return "", err
+	}
+	return wt.Path, nil
+}
+
+// withManifest lays the target's trusted .workspacer.yaml, if any, over its
+// session config and sister repos. A manifest that extends a preset is laid
+// over that preset instead. ask prompts for trust when needed.
+func withManifest(wc config.WorkspaceConfig, presets map[string]config.SessionConfig, target string, sc config.SessionConfig, sisters []config.SisterRepoConfig, ask bool) (config.SessionConfig, []config.SisterRepoConfig) {
+	if target == "root" {
+		return sc, sisters
+	}
+	dir, err := checkoutPath(wc, target)
+	if err != nil {
+		return sc, sisters
+	}
+	m, ok := loadManifest(dir, ask)
+	if !ok {
+		return sc, sisters
+	}
+	if m.Extends != "" {
+		if base, ok := presets[m.Extends]; ok {
+			sc = base
+		} else {
+			fmt.Printf("%s extends unknown session preset %s, laying it over the project's\n", config.ManifestFile, m.Extends)
 		}
-		return
 	}
+	return config.MergeSession(sc, m.SessionConfig), mergeSisterRepos(sisters, m.SisterRepos)
+}
+
+// sessionEnv is the environment a project session starts with: the
+// WORKSPACER_* identity vars, then env from the workspace, preset and project,
+// later ones overriding earlier. A worktree ("project@branch") gets its
+// project's env.
+func sessionEnv(wc config.WorkspaceConfig, sessionConfig config.SessionConfig, project string) map[string]string {
+	env := map[string]string{
+		"WORKSPACER_WORKSPACE": wc.Name,
+		"WORKSPACER_PROJECT":   project,
+	}
+	base, _ := SplitWorktree(project)
+	pc, _ := util.GetProjectConfig(wc, base)
+	for _, m := range []map[string]string{wc.Env, sessionConfig.Env, pc.Env} {
+		for k, v := range m {
+			env[k] = v
+		}
+	}
+	return env
+}
+
+// workspaceSessionConfig is the session layout a workspace's projects get: the
+// named preset, else the inline session_config.
+func workspaceSessionConfig(wc config.WorkspaceConfig, presets map[string]config.SessionConfig) config.SessionConfig {
+	if wc.SessionPreset != "" {
+		return presets[wc.SessionPreset]
+	} else if wc.Session != nil {
+		return *wc.Session
+	}
+	return config.SessionConfig{}
+}
+
+// projectSessionConfig is the session layout a project (or one of its
+// worktrees) gets: its own session_preset when set, else the workspace's.
+func projectSessionConfig(wc config.WorkspaceConfig, presets map[string]config.SessionConfig, project string) config.SessionConfig {
+	base, _ := SplitWorktree(project)
+	pc, _ := util.GetProjectConfig(wc, base)
+	if pc.SessionPreset == "" {
+		return workspaceSessionConfig(wc, presets)
+	}
+	if preset, ok := presets[pc.SessionPreset]; ok {
+		return preset
+	}
+	fmt.Printf("Session preset %s of %s not found, using the workspace's\n", pc.SessionPreset, base)
+	return workspaceSessionConfig(wc, presets)
+}
+
+// StartOrSwitchToTmuxPreset builds (or attaches to) a session from a standalone
+// preset rooted at basePath. No workspace config, so the backend comes from mux
+// (the global default_multiplexer).
+func StartOrSwitchToTmuxPreset(name string, basePath string, preset config.SessionConfig, mux config.MuxBackend) {
+	name = sanitizeTmuxName(name)
+	be := GetBackendByName(mux)
 
 	basePath, err := util.ExpandTilde(basePath)
 	if err != nil {
 		fmt.Println("Error expanding user home folder")
 		return
 	}
+	target := hookTarget{Project: name, Session: name, Path: basePath}
+
+	if be.HasSession(name) {
+		reportHooks(preset.Hooks, HookPreAttach, target)
+		if err := be.Attach(name); err != nil {
+			fmt.Println("error ", err.Error())
+		}
+		return
+	}
 
-	spec := SessionSpec{Name: name, Path: basePath}
+	spec := SessionSpec{Name: name, Path: basePath, Env: preset.Env}
+	data := newTemplateData("", name, basePath, preset.Env)
 	for _, w := range preset.Windows {
+		w = data.window(w)
 		wp := w.Path
 		if wp == "" {
 			wp = basePath
@@ -101,15 +292,21 @@ func StartOrSwitchToTmuxPreset(name string, basePath string, preset config.Sessi
 		}
 		ws := WindowSpec{Name: w.Name, Layout: w.Layout, Path: wp}
 		for _, p := range w.Panes {
-			ws.Panes = append(ws.Panes, PaneSpec{Command: p.Command, Size: p.Size})
+			ws.Panes = append(ws.Panes, paneSpec(p, p.Command, wp))
 		}
 		spec.Windows = append(spec.Windows, ws)
 	}
 
+	if err := runHooks(preset.Hooks, HookPreCreate, target); err != nil {
+		fmt.Println(err)
+		return
+	}
 	if err := be.CreateSession(spec); err != nil {
 		fmt.Println(err)
 		return
 	}
+	reportHooks(preset.Hooks, HookPostCreate, target)
+	reportHooks(preset.Hooks, HookPreAttach, target)
 	if err := be.Attach(name); err != nil {
 		fmt.Println("error ", err.Error())
 	}
@@ -131,9 +328,13 @@ func StartOrSwitchToSession(
 		}
 	}
 
-	rootMode := project == ""
-	if rootMode {
-		project = "root"
+	// name is what the session is for: the project, or "project@branch" for
+	// one of its worktrees.
+	name := project
+	project, _ = SplitWorktree(project)
+
+	if project == "" {
+		name = "root"
 		if _, err := os.Stat(util.GetWorkspacePath(wc)); os.IsNotExist(err) {
 			fmt.Printf("\n\nWorkspace root does not exist\n\n")
 			return
@@ -143,51 +344,61 @@ func StartOrSwitchToSession(
 		return
 	}
 
-	sessionName := sanitizeTmuxName(project)
+	path, err := targetPath(wc, name)
+	if err != nil {
+		fmt.Printf("\n\n%s\n\n", err)
+		return
+	}
+
+	sessionName := sanitizeTmuxName(name)
 	if wc.Prefix != "" {
 		sessionName = sanitizeTmuxName(wc.Prefix) + "-" + sessionName
 	}
 
-	be := GetBackend()
+	sessionConfig, sisterRepos := withManifest(wc, presets, name,
+		projectSessionConfig(wc, presets, name),
+		util.GetSisterReposForProject(wc, project),
+		true,
+	)
+	hooks := mergeHooks(wc.Hooks, sessionConfig.Hooks)
+	target := hookTarget{Workspace: wc.Name, Project: name, Session: sessionName, Path: path}
+
+	be := GetBackend(wc)
 	if be.HasSession(sessionName) {
+		reportHooks(hooks, HookPreAttach, target)
 		if err := be.Attach(sessionName); err != nil {
 			fmt.Println("error ", err.Error())
 		}
 		return
 	}
 
-	path := util.GetWorkspacePath(wc)
-	if !rootMode {
-		path = filepath.Join(path, project)
-	}
-
-	var sessionConfig config.SessionConfig
-	if wc.SessionPreset != "" {
-		sessionConfig = presets[wc.SessionPreset]
-	} else if wc.Session != nil {
-		sessionConfig = *wc.Session
+	spec := SessionSpec{
+		Name: sessionName,
+		Path: path,
+		Env:  sessionEnv(wc, sessionConfig, name),
+		Meta: SessionMeta{Workspace: wc.Key, Project: name},
 	}
 
-	spec := SessionSpec{Name: sessionName, Path: path}
+	data := newTemplateData(wc.Name, project, path, spec.Env)
+	data.File, data.Extra = fileOption, extraVimCommands
+	data.addSisters(wc, sisterRepos)
 
 	// Main windows (first window's name is overridden with the project name).
 	for i, w := range sessionConfig.Windows {
+		w = data.window(w)
 		wname := w.Name
 		if i == 0 {
-			wname = project
+			wname = name
 		}
 		ws := WindowSpec{Name: wname, Layout: w.Layout}
 		for _, p := range w.Panes {
-			ws.Panes = append(ws.Panes, PaneSpec{
-				Command: applyVimArgs(p.Command, fileOption, extraVimCommands),
-				Size:    p.Size,
-			})
+			ws.Panes = append(ws.Panes, paneSpec(p, applyVimArgs(p.Command, fileOption, extraVimCommands), path))
 		}
 		spec.Windows = append(spec.Windows, ws)
 	}
 
 	// Sister repos add windows rooted in their own paths.
-	for _, sr := range util.GetSisterReposForProject(wc, project) {
+	for _, sr := range sisterRepos {
 		if !util.DoesProjectExist(wc, sr.Name) {
 			continue
 		}
@@ -201,14 +412,21 @@ func StartOrSwitchToSession(
 		}
 
 		if len(sisterCfg.Windows) > 0 {
+			sisterData := data
+			sisterData.Path = sisterPath
+			sisterData.Branch = ""
+			if s, err := readGitStatus(sisterPath); err == nil {
+				sisterData.Branch = s.branch
+			}
 			for i, w := range sisterCfg.Windows {
+				w = sisterData.window(w)
 				wname := w.Name
 				if i == 0 {
 					wname = sr.Label
 				}
 				ws := WindowSpec{Name: wname, Layout: w.Layout, Path: sisterPath}
 				for _, p := range w.Panes {
-					ws.Panes = append(ws.Panes, PaneSpec{Command: p.Command, Size: p.Size})
+					ws.Panes = append(ws.Panes, paneSpec(p, p.Command, sisterPath))
 				}
 				spec.Windows = append(spec.Windows, ws)
 			}
@@ -222,10 +440,18 @@ func StartOrSwitchToSession(
 		}
 	}
 
+	// A failed pre_create aborts before anything is built; later hooks only
+	// report, so a session that was created is always left intact and attached.
+	if err := runHooks(hooks, HookPreCreate, target); err != nil {
+		fmt.Println(err)
+		return
+	}
 	if err := be.CreateSession(spec); err != nil {
 		fmt.Println(err)
 		return
 	}
+	reportHooks(hooks, HookPostCreate, target)
+	reportHooks(hooks, HookPreAttach, target)
 	if err := be.Attach(sessionName); err != nil {
 		fmt.Println("error ", err.Error())
 	}
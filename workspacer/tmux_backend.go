@@ -5,13 +5,18 @@ import (
 	"fmt"
 	"os"
 	"os/exec"
+	"strconv"
+	"strings"
+	"time"
 
+	"github.com/JamesTiberiusKirk/workspacer/config"
 	gotmux "github.com/jubnzv/go-tmux"
 )
 
-// tmuxBackend drives real tmux via go-tmux (declarative Configuration.Apply)
-// plus a few direct tmux commands. This is the logic that used to live inline in
-// tmux.go's StartOrSwitchTo* functions, moved behind SessionBackend unchanged.
+// tmuxBackend drives real tmux: go-tmux for listing/killing sessions, direct
+// tmux commands to build them. Sessions used to be built with go-tmux's
+// declarative Configuration.Apply, but that can't pass new-session -e (session
+// environment) and only addresses windows/panes by index.
 type tmuxBackend struct{}
 
 func newTmuxBackend() *tmuxBackend { return &tmuxBackend{} }
@@ -33,9 +38,14 @@ func (b *tmuxBackend) HasSession(name string) bool {
 }
 
 func (b *tmuxBackend) ListSessions() ([]string, error) {
+	// list-sessions exits non-zero when no server is running, which is not an
+	// error for us.
 	server := new(gotmux.Server)
 	sessions, err := server.ListSessions()
 	if err != nil {
+		if _, ok := err.(*exec.ExitError); ok {
+			return []string{}, nil
+		}
 		return nil, err
 	}
 	names := make([]string, len(sessions))
@@ -51,63 +61,92 @@ func (b *tmuxBackend) KillSession(name string) error {
 }
 
 func (b *tmuxBackend) CreateSession(spec SessionSpec) error {
-	server := new(gotmux.Server)
-
-	windows := make([]gotmux.Window, 0, len(spec.Windows))
+	// Build each window's panes, keeping their real IDs (@N/%N) so splits,
+	// sizes and commands hit the right pane whatever base-index, layout or pane
+	// count.
+	windowIDs := make([]string, len(spec.Windows))
+	paneIDs := make([][]string, len(spec.Windows))
 	for i, w := range spec.Windows {
-		dir := w.Path
-		if dir == "" {
-			dir = spec.Path
+		var args []string
+		if i == 0 {
+			// -e puts the vars in the session environment, so every window and
+			// pane (and thus every pane command) starts with them.
+			args = []string{"new-session", "-d", "-s", spec.Name}
+			for _, kv := range spec.envList() {
+				args = append(args, "-e", kv)
+			}
+		} else {
+			args = []string{"new-window", "-d", "-t", spec.Name + ":"}
 		}
-		windows = append(windows, gotmux.Window{
-			Id:             i + 1,
-			Name:           w.Name,
-			Layout:         w.Layout,
-			Panes:          make([]gotmux.Pane, len(w.Panes)),
-			StartDirectory: dir,
-		})
-	}
-
-	session := gotmux.NewSession(0, spec.Name, spec.Path, windows)
-	server.AddSession(*session)
-	conf := gotmux.Configuration{Server: server, Sessions: []*gotmux.Session{session}, ActiveSession: nil}
-	if err := conf.Apply(); err != nil {
-		return fmt.Errorf("apply tmux configuration: %w", err)
-	}
-
-	// Run each pane's command and size it, window by window.
-	sessWindows, err := session.ListWindows()
-	if err != nil {
-		return fmt.Errorf("list windows: %w", err)
-	}
-	for wi, w := range spec.Windows {
-		if wi >= len(sessWindows) {
-			break
+		if w.Name != "" {
+			args = append(args, "-n", w.Name)
 		}
-		wPanes, err := sessWindows[wi].ListPanes()
+		args = append(args, "-c", spec.firstPaneDir(w), "-P", "-F", "#{window_id} #{pane_id} #{session_id}")
+
+		out, errStr, err := tmuxCmd(args)
 		if err != nil {
-			continue
+			return fmt.Errorf("%s %s: %w %s", args[0], spec.Name, err, errStr)
+		}
+		ids := strings.Fields(out)
+		if len(ids) != 3 {
+			return fmt.Errorf("%s %s: unexpected output %q", args[0], spec.Name, out)
 		}
+		windowIDs[i] = ids[0]
+		paneIDs[i] = []string{ids[1]}
+		if i == 0 && spec.Meta.Workspace != "" {
+			b.tag(ids[2], spec.Meta)
+		}
+
 		for pi, p := range w.Panes {
-			if pi >= len(wPanes) {
-				break
+			if pi == 0 {
+				continue
 			}
-			if p.Size > 0 && p.Size < 100 {
-				b.paneSize(p.Size)
+			// Split the pane created last, so orientations chain.
+			prev := paneIDs[i][len(paneIDs[i])-1]
+			args := []string{"split-window", "-d", "-P", "-F", "#{pane_id}", "-t", prev, "-c", spec.paneDir(w, p)}
+			switch p.Orientation {
+			case config.OrientationHorizontal:
+				args = append(args, "-h")
+			case config.OrientationVertical:
+				args = append(args, "-v")
 			}
-			if p.Command != "" {
-				wPanes[pi].RunCommand(p.Command)
+			id, errStr, err := tmuxCmd(args)
+			if err != nil {
+				return fmt.Errorf("split pane %s: %w %s", prev, err, errStr)
+			}
+			paneIDs[i] = append(paneIDs[i], strings.TrimSpace(id))
+		}
+		if w.Layout != "" {
+			if _, errStr, err := tmuxCmd([]string{"select-layout", "-t", windowIDs[i], w.Layout}); err != nil {
+				return fmt.Errorf("select layout %s: %w %s", windowIDs[i], err, errStr)
 			}
 		}
 	}
 
-	// Focus the first window/pane (tmux leaves the last-built one active).
-	if len(sessWindows) > 0 {
-		sessWindows[0].Select()
-		if p, err := sessWindows[0].ListPanes(); err == nil && len(p) > 0 {
-			p[0].Select()
+	// Size panes once every split exists (a later split would undo an earlier
+	// resize), then run their commands.
+	for i, w := range spec.Windows {
+		for pi, p := range w.Panes {
+			if pi < len(paneIDs[i]) {
+				b.paneSize(paneIDs[i][pi], p)
+			}
+		}
+	}
+	for i, w := range spec.Windows {
+		for pi, p := range w.Panes {
+			if pi < len(paneIDs[i]) && p.Command != "" {
+				if _, errStr, err := tmuxCmd([]string{"send-keys", "-t", paneIDs[i][pi], p.Command, "C-m"}); err != nil {
+					fmt.Printf("run command in pane %s: %s %s\n", paneIDs[i][pi], err, errStr)
+				}
+			}
 		}
 	}
+
+	// Focus the first window/pane.
+	if len(windowIDs) > 0 {
+		tmuxCmd([]string{"select-window", "-t", windowIDs[0]})
+		tmuxCmd([]string{"select-pane", "-t", paneIDs[0][0]})
+	}
 	return nil
 }
 
@@ -122,17 +161,163 @@ func (b *tmuxBackend) Attach(name string) error {
 	return cmd.Run()
 }
 
-// paneSize resizes the pane to the right to size% width (the old paneSize()
-// helper, unchanged — it targets {right}, assuming the common two-pane split).
-func (b *tmuxBackend) paneSize(size int) {
-	out, errStr, errExec := tmuxCmd([]string{"resizep", "-t{right}", "-x " + fmt.Sprint(size) + "%"})
-	if errExec != nil {
-		fmt.Print(errExec.Error())
+// CaptureSession describes a running session for snapshots: its environment,
+// every window with its exact layout string and, per pane, the cwd and the
+// full command line of whatever runs in the pane's shell.
+func (b *tmuxBackend) CaptureSession(name string) (SessionSnapshot, error) {
+	format := strings.Join([]string{
+		"#{session_path}",
+		"#{window_index}",
+		"#{window_name}",
+		"#{window_layout}",
+		"#{pane_current_path}",
+		"#{pane_current_command}",
+		"#{pane_pid}",
+	}, "\t")
+	out, errStr, err := tmuxCmd([]string{"list-panes", "-s", "-t", "=" + name, "-F", format})
+	if err != nil {
+		return SessionSnapshot{}, fmt.Errorf("tmux list-panes: %w %s", err, errStr)
+	}
+
+	snap := SessionSnapshot{Name: name}
+	lastWindow := ""
+	for _, line := range strings.Split(strings.TrimSpace(out), "\n") {
+		f := strings.Split(line, "\t")
+		if len(f) != 7 {
+			continue
+		}
+		snap.Path = f[0]
+		if f[1] != lastWindow {
+			snap.Windows = append(snap.Windows, WindowSnapshot{Name: f[2], Layout: f[3]})
+			lastWindow = f[1]
+		}
+		w := &snap.Windows[len(snap.Windows)-1]
+		w.Panes = append(w.Panes, PaneSnapshot{Path: f[4], Command: paneCommand(f[6], f[5])})
+	}
+	snap.Env = sessionEnvironment(name)
+	return snap, nil
+}
+
+// sessionEnvironment reads the vars set on a session, as new-session -e does.
+// The ones tmux copies from the attaching client (update-environment, e.g.
+// SSH_AUTH_SOCK) are left out: they'd be stale by the time it's restored.
+func sessionEnvironment(name string) map[string]string {
+	out, _, err := tmuxCmd([]string{"show-environment", "-t", "=" + name})
+	if err != nil {
+		return nil
+	}
+	fromClient := map[string]bool{}
+	if upd, _, err := tmuxCmd([]string{"show-options", "-gv", "update-environment"}); err == nil {
+		for _, v := range strings.Fields(upd) {
+			fromClient[v] = true
+		}
+	}
+
+	env := map[string]string{}
+	for _, line := range strings.Split(strings.TrimSpace(out), "\n") {
+		// "-NAME" lines are vars removed from the session, with no "=".
+		k, v, ok := strings.Cut(line, "=")
+		if ok && !fromClient[k] {
+			env[k] = v
+		}
+	}
+	if len(env) == 0 {
+		return nil
+	}
+	return env
+}
+
+// paneCommand returns the command line running in a pane whose shell is pid,
+// or "" when the pane is sitting at a shell prompt. Falls back to tmux's
+// command name when the process table can't be read.
+func paneCommand(pid, current string) string {
+	switch current {
+	case "bash", "zsh", "fish", "sh", "dash", "ksh", "nu":
+		return ""
+	}
+
+	children, err := exec.Command("pgrep", "-P", pid).Output()
+	if err != nil {
+		return current
+	}
+	child := strings.TrimSpace(strings.Split(string(children), "\n")[0])
+	if child == "" {
+		return current
+	}
+	args, err := exec.Command("ps", "-o", "args=", "-p", child).Output()
+	if err != nil || strings.TrimSpace(string(args)) == "" {
+		return current
+	}
+	return strings.TrimSpace(string(args))
+}
+
+// tag stores meta on the session with the given ID ($N) as the user options
+// @workspacer_workspace and @workspacer_project. A failure is printed; the
+// session then falls back to being matched by name.
+func (b *tmuxBackend) tag(id string, meta SessionMeta) {
+	for opt, v := range map[string]string{"@workspacer_workspace": meta.Workspace, "@workspacer_project": meta.Project} {
+		if _, errStr, err := tmuxCmd([]string{"set-option", "-t", id, opt, v}); err != nil {
+			fmt.Printf("tag session %s: %s %s\n", id, err, errStr)
+		}
+	}
+}
+
+// SessionTags reads every session's @workspacer_* options in one list-sessions.
+func (b *tmuxBackend) SessionTags() (map[string]SessionMeta, error) {
+	out, errStr, err := tmuxCmd([]string{"list-sessions", "-F", "#{session_name}\t#{@workspacer_workspace}\t#{@workspacer_project}"})
+	if _, ok := err.(*exec.ExitError); ok {
+		return map[string]SessionMeta{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("tmux list-sessions: %w %s", err, errStr)
+	}
+	return parseSessionTags(out), nil
+}
+
+// DescribeSessions reads window count, attached clients and last activity of
+// every session in one list-sessions.
+func (b *tmuxBackend) DescribeSessions() (map[string]SessionInfo, error) {
+	out, errStr, err := tmuxCmd([]string{"list-sessions", "-F", "#{session_name}\t#{session_windows}\t#{session_attached}\t#{session_activity}"})
+	info := map[string]SessionInfo{}
+	if err != nil {
+		if _, ok := err.(*exec.ExitError); ok {
+			return info, nil
+		}
+		return nil, fmt.Errorf("tmux list-sessions: %w %s", err, errStr)
+	}
+
+	for _, line := range strings.Split(strings.TrimSpace(out), "\n") {
+		f := strings.Split(line, "\t")
+		if len(f) != 4 {
+			continue
+		}
+		windows, _ := strconv.Atoi(f[1])
+		attached, _ := strconv.Atoi(f[2])
+		activity, _ := strconv.ParseInt(f[3], 10, 64)
+		isAttached := attached > 0
+		info[f[0]] = SessionInfo{Windows: windows, Attached: &isAttached, LastActivity: time.Unix(activity, 0)}
+	}
+	return info, nil
+}
+
+// paneSize resizes the pane with the given ID to p's width/height. Targeting
+// by ID is window-scoped, so any pane of any window can be sized. Failures are
+// printed and otherwise ignored, as a wrong size shouldn't abort the build.
+func (b *tmuxBackend) paneSize(id string, p PaneSpec) {
+	if p.Width == "" && p.Height == "" {
+		return
+	}
+
+	args := []string{"resize-pane", "-t", id}
+	if p.Width != "" {
+		args = append(args, "-x", p.Width)
+	}
+	if p.Height != "" {
+		args = append(args, "-y", p.Height)
 	}
-	if errStr != "" {
-		fmt.Print(errStr)
+	if _, errStr, err := tmuxCmd(args); err != nil {
+		fmt.Printf("resize pane %s: %s %s\n", id, err, errStr)
 	}
-	fmt.Print(out)
 }
 
 // tmuxCmd runs a raw tmux command (the old RunCmd helper).
@@ -0,0 +1,258 @@
+package workspacer
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/JamesTiberiusKirk/workspacer/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// useRecorder routes GetBackend to a fresh RecordingBackend for one test.
+func useRecorder(t *testing.T, existing ...string) *RecordingBackend {
+	t.Helper()
+	rec := NewRecordingBackend(nil, nil)
+	rec.Existing = existing
+	backendOverride = rec
+	t.Cleanup(func() { backendOverride = nil })
+	return rec
+}
+
+// testWorkspace creates a workspace dir containing the given project dirs.
+func testWorkspace(t *testing.T, projects ...string) config.WorkspaceConfig {
+	t.Helper()
+	root := t.TempDir()
+	for _, p := range projects {
+		require.NoError(t, os.MkdirAll(filepath.Join(root, p), 0755))
+	}
+	return config.WorkspaceConfig{Key: "work", Name: "work", Prefix: "wk", Path: root}
+}
+
+func TestStartOrSwitchToSession(t *testing.T) {
+	presets := map[string]config.SessionConfig{
+		"go": {Windows: []config.WindowConfig{
+			{Name: "editor", Layout: "main-vertical", Panes: []config.PanesConfig{
+				{Command: "nvim", Size: 60},
+				{},
+			}},
+			{Name: "server", Panes: []config.PanesConfig{{Command: "go run ."}}},
+		}},
+		"web": {Windows: []config.WindowConfig{
+			{Name: "dev", Panes: []config.PanesConfig{{Command: "npm run dev"}}},
+		}},
+	}
+
+	t.Run("Builds_preset_windows", func(t *testing.T) {
+		rec := useRecorder(t)
+		wc := testWorkspace(t, "api")
+		wc.SessionPreset = "go"
+
+		StartOrSwitchToSession(wc, presets, "api:main.go")
+
+		require.Len(t, rec.Specs, 1)
+		spec := rec.Specs[0]
+		assert.Equal(t, "wk-api", spec.Name)
+		assert.Equal(t, filepath.Join(wc.Path, "api"), spec.Path)
+		require.Len(t, spec.Windows, 2)
+		assert.Equal(t, "api", spec.Windows[0].Name, "first window takes the project name")
+		assert.Equal(t, "main-vertical", spec.Windows[0].Layout)
+		assert.Equal(t, []PaneSpec{{Command: "nvim ./main.go", Width: "60%"}, {}}, spec.Windows[0].Panes)
+		assert.Equal(t, "server", spec.Windows[1].Name)
+		assert.Equal(t, []RecordedCall{{Op: "create", Name: "wk-api"}, {Op: "attach", Name: "wk-api"}}, rec.Calls)
+	})
+
+	t.Run("Adds_sister_repo_windows", func(t *testing.T) {
+		rec := useRecorder(t)
+		wc := testWorkspace(t, "api", "frontend", "infra")
+		wc.SessionPreset = "go"
+		wc.Projects = []config.ProjectConfig{{
+			Name: "api",
+			SisterRepos: []config.SisterRepoConfig{
+				{Name: "frontend", Label: "fe", SessionPreset: "web"},
+				{Name: "infra", Label: "infra"},
+				{Name: "missing", Label: "gone"},
+			},
+		}}
+
+		StartOrSwitchToSession(wc, presets, "api")
+
+		require.Len(t, rec.Specs, 1)
+		windows := rec.Specs[0].Windows
+		require.Len(t, windows, 4)
+		assert.Equal(t, WindowSpec{
+			Name:  "fe",
+			Path:  filepath.Join(wc.Path, "frontend"),
+			Panes: []PaneSpec{{Command: "npm run dev"}},
+		}, windows[2])
+		assert.Equal(t, WindowSpec{
+			Name:   "infra",
+			Layout: "even-horizontal",
+			Path:   filepath.Join(wc.Path, "infra"),
+			Panes:  []PaneSpec{{}},
+		}, windows[3])
+	})
+
+	t.Run("Project_preset_overrides_workspace", func(t *testing.T) {
+		rec := useRecorder(t)
+		wc := testWorkspace(t, "api", "frontend")
+		wc.SessionPreset = "go"
+		wc.Projects = []config.ProjectConfig{{Name: "frontend", SessionPreset: "web"}}
+
+		StartOrSwitchToSession(wc, presets, "frontend")
+		StartOrSwitchToSession(wc, presets, "api")
+
+		require.Len(t, rec.Specs, 2)
+		assert.Equal(t, []PaneSpec{{Command: "npm run dev"}}, rec.Specs[0].Windows[0].Panes)
+		assert.Len(t, rec.Specs[1].Windows, 2, "projects without a preset keep the workspace's")
+	})
+
+	t.Run("Opens_in_sub_path", func(t *testing.T) {
+		rec := useRecorder(t)
+		wc := testWorkspace(t, "mono/services/api", "other")
+		wc.Session = &config.SessionConfig{Windows: []config.WindowConfig{{Panes: []config.PanesConfig{{Path: "cmd"}}}}}
+		wc.Projects = []config.ProjectConfig{
+			{Name: "mono", SubPath: "services/api"},
+			{Name: "other", SubPath: "missing"},
+		}
+
+		StartOrSwitchToSession(wc, nil, "mono")
+		StartOrSwitchToSession(wc, nil, "other")
+
+		require.Len(t, rec.Specs, 2)
+		sub := filepath.Join(wc.Path, "mono", "services", "api")
+		assert.Equal(t, sub, rec.Specs[0].Path)
+		assert.Equal(t, filepath.Join(sub, "cmd"), rec.Specs[0].Windows[0].Panes[0].Path)
+		assert.Equal(t, filepath.Join(wc.Path, "other"), rec.Specs[1].Path, "a missing sub_path falls back to the project root")
+	})
+
+	t.Run("Resolves_pane_paths_orientation_and_sizes", func(t *testing.T) {
+		rec := useRecorder(t)
+		wc := testWorkspace(t, "app")
+		wc.Session = &config.SessionConfig{Windows: []config.WindowConfig{{Panes: []config.PanesConfig{
+			{Command: "nvim", Size: 50, Width: "120"},
+			{Command: "go run .", Orientation: config.OrientationHorizontal, Height: "70%"},
+			{Path: "./web", Orientation: config.OrientationVertical, Width: "150%"},
+			{Orientation: "diagonal"},
+		}}}}
+
+		StartOrSwitchToSession(wc, nil, "app")
+
+		require.Len(t, rec.Specs, 1)
+		assert.Equal(t, []PaneSpec{
+			{Command: "nvim", Width: "120"},
+			{Command: "go run .", Orientation: config.OrientationHorizontal, Height: "70%"},
+			{Path: filepath.Join(wc.Path, "app", "web"), Orientation: config.OrientationVertical},
+			{},
+		}, rec.Specs[0].Windows[0].Panes)
+	})
+
+	t.Run("Expands_templates", func(t *testing.T) {
+		rec := useRecorder(t)
+		t.Setenv("EDITOR", "hx")
+		wc := testWorkspace(t, "api-docs")
+		initRepo(t, filepath.Join(wc.Path, "api"), 1)
+		wc.Env = map[string]string{"STAGE": "dev"}
+		wc.Projects = []config.ProjectConfig{{
+			Name:        "api",
+			SisterRepos: []config.SisterRepoConfig{{Name: "api-docs", Label: "docs", SessionPreset: "docs"}},
+		}}
+		wc.Session = &config.SessionConfig{Windows: []config.WindowConfig{
+			{Panes: []config.PanesConfig{
+				{Command: "{{.Env.EDITOR}} {{.File}} {{.Extra}}"},
+				{Command: "go run ./cmd/{{.Project}} --stage {{.Env.STAGE}}{{.Env.UNSET}}", Path: "{{.Sisters.docs}}"},
+			}},
+			{Name: "{{.Workspace}}-{{.Branch}}", Panes: []config.PanesConfig{{Command: "echo {{.Path}} {{.Nope}}"}}},
+		}}
+		presets := map[string]config.SessionConfig{
+			"docs": {Windows: []config.WindowConfig{{Panes: []config.PanesConfig{{Command: "ls {{.Path}}"}}}}},
+		}
+
+		StartOrSwitchToSession(wc, presets, "api:main.go:+10")
+
+		require.Len(t, rec.Specs, 1)
+		windows := rec.Specs[0].Windows
+		require.Len(t, windows, 3)
+		docs := filepath.Join(wc.Path, "api-docs")
+		assert.Equal(t, []PaneSpec{
+			{Command: "hx main.go +10"},
+			{Command: "go run ./cmd/api --stage dev", Path: docs},
+		}, windows[0].Panes)
+		assert.Equal(t, "work-main", windows[1].Name)
+		assert.Equal(t, []PaneSpec{{Command: "echo {{.Path}} {{.Nope}}"}}, windows[1].Panes, "a failing template is used as written")
+		assert.Equal(t, []PaneSpec{{Command: "ls " + docs}}, windows[2].Panes, "sister windows get their own path")
+	})
+
+	t.Run("Merges_session_env", func(t *testing.T) {
+		rec := useRecorder(t)
+		wc := testWorkspace(t, "api")
+		wc.Env = map[string]string{"STAGE": "dev", "REGION": "eu"}
+		wc.Session = &config.SessionConfig{Env: map[string]string{"STAGE": "test"}}
+		wc.Projects = []config.ProjectConfig{{Name: "api", Env: map[string]string{"REGION": "us"}}}
+
+		StartOrSwitchToSession(wc, presets, "api")
+
+		require.Len(t, rec.Specs, 1)
+		assert.Equal(t, map[string]string{
+			"WORKSPACER_WORKSPACE": "work",
+			"WORKSPACER_PROJECT":   "api",
+			"STAGE":                "test",
+			"REGION":               "us",
+		}, rec.Specs[0].Env)
+	})
+
+	t.Run("Runs_hooks_with_session_env", func(t *testing.T) {
+		rec := useRecorder(t)
+		wc := testWorkspace(t, "api")
+		wc.Hooks.PostCreate = []string{`echo "$WORKSPACER_WORKSPACE $WORKSPACER_PROJECT $WORKSPACER_SESSION" > hook.out`}
+
+		StartOrSwitchToSession(wc, presets, "api")
+
+		require.Len(t, rec.Specs, 1)
+		out, err := os.ReadFile(filepath.Join(wc.Path, "api", "hook.out"))
+		require.NoError(t, err)
+		assert.Equal(t, "work api wk-api\n", string(out))
+	})
+
+	t.Run("Failed_pre_create_hook_aborts", func(t *testing.T) {
+		rec := useRecorder(t)
+		wc := testWorkspace(t, "api")
+		wc.Hooks.PreCreate = []string{"exit 1"}
+
+		StartOrSwitchToSession(wc, presets, "api")
+
+		assert.Empty(t, rec.Calls)
+	})
+
+	t.Run("Attaches_to_existing_session", func(t *testing.T) {
+		rec := useRecorder(t, "wk-api")
+		wc := testWorkspace(t, "api")
+
+		StartOrSwitchToSession(wc, presets, "api")
+
+		assert.Empty(t, rec.Specs)
+		assert.Equal(t, []RecordedCall{{Op: "attach", Name: "wk-api"}}, rec.Calls)
+	})
+
+	t.Run("Skips_missing_project", func(t *testing.T) {
+		rec := useRecorder(t)
+		wc := testWorkspace(t)
+
+		StartOrSwitchToSession(wc, presets, "nope")
+
+		assert.Empty(t, rec.Calls)
+	})
+}
+
+func TestCloseAllSessionsInWorkspace(t *testing.T) {
+	rec := useRecorder(t, "wk-api", "wk-web", "personal-dots")
+	wc := testWorkspace(t)
+
+	CloseAllSessionsInWorkspace(wc, nil)
+
+	assert.Equal(t, []RecordedCall{{Op: "kill", Name: "wk-api"}, {Op: "kill", Name: "wk-web"}}, rec.Calls)
+	names, err := rec.ListSessions()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"personal-dots"}, names)
+}
@@ -0,0 +1,129 @@
+package workspacer
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/JamesTiberiusKirk/workspacer/config"
+	"github.com/JamesTiberiusKirk/workspacer/util"
+)
+
+// worktreeDir is the workspace folder `worktree add` creates worktrees in. It
+// is hidden so the picker doesn't list it as a project.
+const worktreeDir = ".worktrees"
+
+// Worktree is a linked git worktree of a project (the main checkout isn't
+// one).
+type Worktree struct {
+	Path   string
+	Branch string // short branch name, "" when detached
+}
+
+// Name is how the worktree is addressed after "<project>@": its branch, or
+// its folder name when detached.
+func (w Worktree) Name() string {
+	if w.Branch != "" {
+		return w.Branch
+	}
+	return filepath.Base(w.Path)
+}
+
+// SplitWorktree splits a "project@branch" target into the project and the
+// worktree name; name is "" for a plain project.
+func SplitWorktree(target string) (project, name string) {
+	project, name, _ = strings.Cut(target, "@")
+	return project, name
+}
+
+// hasWorktrees is a cheap check for linked worktrees, so the picker only runs
+// git for projects that have some.
+func hasWorktrees(projectPath string) bool {
+	entries, err := os.ReadDir(filepath.Join(projectPath, ".git", "worktrees"))
+	return err == nil && len(entries) > 0
+}
+
+// ListWorktrees returns the linked worktrees of the project at projectPath.
+func ListWorktrees(projectPath string) ([]Worktree, error) {
+	out, err := exec.Command("git", "-C", projectPath, "worktree", "list", "--porcelain").Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list worktrees: %w", err)
+	}
+	return parseWorktrees(string(out)), nil
+}
+
+// parseWorktrees parses `git worktree list --porcelain`, skipping the main
+// worktree (always first) and bare entries.
+func parseWorktrees(out string) []Worktree {
+	worktrees := []Worktree{}
+	for i, block := range strings.Split(strings.TrimSpace(out), "\n\n") {
+		if i == 0 {
+			continue
+		}
+		var wt Worktree
+		bare := false
+		for _, line := range strings.Split(block, "\n") {
+			key, value, _ := strings.Cut(line, " ")
+			switch key {
+			case "worktree":
+				wt.Path = value
+			case "branch":
+				wt.Branch = strings.TrimPrefix(value, "refs/heads/")
+			case "bare":
+				bare = true
+			}
+		}
+		if wt.Path != "" && !bare {
+			worktrees = append(worktrees, wt)
+		}
+	}
+	return worktrees
+}
+
+// FindWorktree returns the worktree of project named name (see
+// Worktree.Name).
+func FindWorktree(wc config.WorkspaceConfig, project, name string) (Worktree, error) {
+	worktrees, err := ListWorktrees(filepath.Join(util.GetWorkspacePath(wc), project))
+	if err != nil {
+		return Worktree{}, err
+	}
+	for _, wt := range worktrees {
+		if wt.Name() == name {
+			return wt, nil
+		}
+	}
+	return Worktree{}, fmt.Errorf("project %s has no worktree %s", project, name)
+}
+
+// AddWorktree checks out branch of project in a new worktree under the
+// workspace's .worktrees folder, creating the branch from HEAD when neither
+// it nor origin/<branch> exists. An existing worktree of branch is returned
+// as is.
+func AddWorktree(wc config.WorkspaceConfig, project, branch string) (Worktree, error) {
+	if !util.DoesProjectExist(wc, project) {
+		return Worktree{}, fmt.Errorf("project %s does not exist", project)
+	}
+	if wt, err := FindWorktree(wc, project, branch); err == nil {
+		return wt, nil
+	}
+
+	projectPath := filepath.Join(util.GetWorkspacePath(wc), project)
+	path := filepath.Join(util.GetWorkspacePath(wc), worktreeDir, project, strings.ReplaceAll(branch, "/", "-"))
+
+	args := []string{"-C", projectPath, "worktree", "add"}
+	if !refExists(projectPath, "refs/heads/"+branch) && !refExists(projectPath, "refs/remotes/origin/"+branch) {
+		args = append(args, "-b", branch, path)
+	} else {
+		args = append(args, path, branch)
+	}
+	if out, err := exec.Command("git", args...).CombinedOutput(); err != nil {
+		return Worktree{}, fmt.Errorf("failed to add worktree: %w: %s", err, strings.TrimSpace(string(out)))
+	}
+	return Worktree{Path: path, Branch: branch}, nil
+}
+
+func refExists(repoPath, ref string) bool {
+	return exec.Command("git", "-C", repoPath, "rev-parse", "--verify", "--quiet", ref).Run() == nil
+}
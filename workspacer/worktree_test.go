@@ -0,0 +1,90 @@
+package workspacer
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseWorktrees(t *testing.T) {
+	out := `worktree /src/api
+HEAD 1111111111111111111111111111111111111111
+branch refs/heads/main
+
+worktree /src/.worktrees/api/feature-login
+HEAD 2222222222222222222222222222222222222222
+branch refs/heads/feature/login
+
+worktree /tmp/api-bisect
+HEAD 3333333333333333333333333333333333333333
+detached
+`
+	worktrees := parseWorktrees(out)
+
+	assert.Equal(t, []Worktree{
+		{Path: "/src/.worktrees/api/feature-login", Branch: "feature/login"},
+		{Path: "/tmp/api-bisect"},
+	}, worktrees)
+	assert.Equal(t, "feature/login", worktrees[0].Name())
+	assert.Equal(t, "api-bisect", worktrees[1].Name())
+}
+
+func TestWorktreeSessions(t *testing.T) {
+	wc := testWorkspace(t)
+	initRepo(t, filepath.Join(wc.Path, "api"), 1)
+
+	wt, err := AddWorktree(wc, "api", "feature/login")
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(wc.Path, worktreeDir, "api", "feature-login"), wt.Path)
+	assert.Equal(t, "feature/login", gitRun(t, wt.Path, "branch", "--show-current"))
+
+	t.Run("Add_is_idempotent", func(t *testing.T) {
+		again, err := AddWorktree(wc, "api", "feature/login")
+		require.NoError(t, err)
+		assert.Equal(t, wt.Path, again.Path)
+	})
+
+	t.Run("Listed_in_picker", func(t *testing.T) {
+		useRecorder(t)
+		StartOrSwitchToSession(wc, nil, "api@feature/login")
+		items := worktreeItems(wc, "api", GetOpenProjectsByWorkspace(wc))
+		require.Len(t, items, 1)
+		assert.Equal(t, "api@feature/login (Active)", items[0].Display)
+		assert.Equal(t, "folder:api@feature/login", items[0].Value)
+	})
+
+	t.Run("Opens_session_in_worktree", func(t *testing.T) {
+		rec := useRecorder(t)
+
+		StartOrSwitchToSession(wc, nil, "api@feature/login")
+
+		require.Len(t, rec.Specs, 1)
+		spec := rec.Specs[0]
+		assert.Equal(t, "wk-api@feature_login", spec.Name, "the branch's / can't be in a session name")
+		assert.Equal(t, wt.Path, spec.Path)
+		assert.Equal(t, SessionMeta{Workspace: "work", Project: "api@feature/login"}, spec.Meta)
+	})
+
+	t.Run("Session_name_of_slash_branch", func(t *testing.T) {
+		rec := useRecorder(t)
+		_, err := AddWorktree(wc, "api", "feature/x")
+		require.NoError(t, err)
+
+		StartOrSwitchToSession(wc, nil, "api@feature/x")
+		StartOrSwitchToSession(wc, nil, "api@feature/x")
+
+		require.Len(t, rec.Specs, 1, "the second open finds the session it created")
+		assert.Equal(t, "wk-api@feature_x", rec.Specs[0].Name)
+		assert.Equal(t, []string{"api@feature/x"}, GetOpenProjectsByWorkspace(wc))
+	})
+
+	t.Run("Skips_unknown_worktree", func(t *testing.T) {
+		rec := useRecorder(t)
+
+		StartOrSwitchToSession(wc, nil, "api@nope")
+
+		assert.Empty(t, rec.Calls)
+	})
+}
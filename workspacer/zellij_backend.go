@@ -0,0 +1,313 @@
+package workspacer
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/JamesTiberiusKirk/workspacer/config"
+)
+
+// zellijBackend drives zellij via its CLI. zellij has no imperative "build a
+// window, split a pane" API usable from outside a session, so CreateSession
+// renders the whole SessionSpec into a KDL layout file and starts a background
+// session from it.
+type zellijBackend struct{ bin string }
+
+func newZellijBackend() *zellijBackend {
+	bin := os.Getenv("ZELLIJ_BIN")
+	if bin == "" {
+		bin = "zellij"
+	}
+	return &zellijBackend{bin: bin}
+}
+
+func (b *zellijBackend) HasSession(name string) bool {
+	names, err := b.ListSessions()
+	if err != nil {
+		return false
+	}
+	for _, n := range names {
+		if n == name {
+			return true
+		}
+	}
+	return false
+}
+
+func (b *zellijBackend) ListSessions() ([]string, error) {
+	// -s: names only, -n: no ANSI formatting. Exits non-zero when there are no
+	// sessions, which is not an error for us.
+	out, err := exec.Command(b.bin, "list-sessions", "-s", "-n").Output()
+	if err != nil {
+		if _, ok := err.(*exec.ExitError); ok {
+			return []string{}, nil
+		}
+		return nil, err
+	}
+	var names []string
+	for _, line := range strings.Split(strings.TrimSpace(string(out)), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		names = append(names, line)
+	}
+	return names, nil
+}
+
+// DescribeSessions reports the tab count of every live session, from
+// `list-sessions -n` lines like "<name> [Created 2h ago] (current)", and
+// marks the session this process runs in as attached. zellij doesn't report
+// other sessions' clients or any activity, so those stay unknown.
+func (b *zellijBackend) DescribeSessions() (map[string]SessionInfo, error) {
+	info := map[string]SessionInfo{}
+	out, err := exec.Command(b.bin, "list-sessions", "-n").Output()
+	if err != nil {
+		if _, ok := err.(*exec.ExitError); ok {
+			return info, nil
+		}
+		return nil, err
+	}
+	for _, line := range strings.Split(strings.TrimSpace(string(out)), "\n") {
+		name, rest, _ := strings.Cut(strings.TrimSpace(line), " ")
+		if name == "" || strings.Contains(rest, "EXITED") {
+			continue
+		}
+		var i SessionInfo
+		if tabs, err := exec.Command(b.bin, "--session", name, "action", "query-tab-names").Output(); err == nil {
+			for _, tab := range strings.Split(string(tabs), "\n") {
+				if strings.TrimSpace(tab) != "" {
+					i.Windows++
+				}
+			}
+		}
+		if strings.Contains(rest, "(current)") {
+			attached := true
+			i.Attached = &attached
+		}
+		info[name] = i
+	}
+	return info, nil
+}
+
+func (b *zellijBackend) KillSession(name string) error {
+	return exec.Command(b.bin, "kill-session", name).Run()
+}
+
+func (b *zellijBackend) CreateSession(spec SessionSpec) error {
+	layoutPath, err := writeZellijLayout(spec)
+	if err != nil {
+		return err
+	}
+
+	// --create-background builds the session without attaching; the layout is
+	// passed through the attach-time options.
+	cmd := exec.Command(b.bin, "attach", "--create-background", spec.Name,
+		"options", "--default-layout", layoutPath)
+	cmd.Dir = spec.Path
+	// The background session's server, and so every pane, inherits these.
+	cmd.Env = append(os.Environ(), spec.envList()...)
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("zellij attach --create-background %s: %w %s", spec.Name, err, out)
+	}
+	return nil
+}
+
+func (b *zellijBackend) Attach(name string) error {
+	// ponytail: zellij can't switch an existing client to another session from
+	// the CLI, so this always attaches (nested when already inside zellij), the
+	// same compromise the gtmux backend makes.
+	cmd := exec.Command(b.bin, "attach", name)
+	cmd.Stdin, cmd.Stdout, cmd.Stderr = os.Stdin, os.Stdout, os.Stderr
+	return cmd.Run()
+}
+
+// writeZellijLayout renders spec to <user cache>/workspacer/zellij/<name>.kdl
+// and returns the file path. The file is rewritten on every create.
+func writeZellijLayout(spec SessionSpec) (string, error) {
+	cacheDir, err := os.UserCacheDir()
+	if err != nil {
+		return "", fmt.Errorf("could not get cache directory: %w", err)
+	}
+	dir := filepath.Join(cacheDir, "workspacer", "zellij")
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", fmt.Errorf("could not create layout directory: %w", err)
+	}
+
+	path := filepath.Join(dir, spec.Name+".kdl")
+	if err := os.WriteFile(path, []byte(zellijLayout(spec)), 0644); err != nil {
+		return "", fmt.Errorf("could not write zellij layout: %w", err)
+	}
+	return path, nil
+}
+
+// zellijLayout translates a SessionSpec into a zellij KDL layout: one tab per
+// WindowSpec, the tmux layout name mapped onto zellij split directions, and
+// pane commands run inside the user's shell so the pane survives the command
+// exiting (matching tmux, where commands are typed into a live shell).
+func zellijLayout(spec SessionSpec) string {
+	var sb strings.Builder
+
+	sb.WriteString("layout {\n")
+	if spec.Path != "" {
+		sb.WriteString(fmt.Sprintf("    cwd %s\n", kdlQuote(spec.Path)))
+	}
+
+	// Keep zellij's tab and status bars, which a custom layout otherwise drops.
+	sb.WriteString("    default_tab_template {\n")
+	sb.WriteString("        pane size=1 borderless=true {\n")
+	sb.WriteString("            plugin location=\"zellij:tab-bar\"\n")
+	sb.WriteString("        }\n")
+	sb.WriteString("        children\n")
+	sb.WriteString("        pane size=2 borderless=true {\n")
+	sb.WriteString("            plugin location=\"zellij:status-bar\"\n")
+	sb.WriteString("        }\n")
+	sb.WriteString("    }\n")
+
+	for i, w := range spec.Windows {
+		dir := spec.windowDir(w)
+
+		sb.WriteString("    tab")
+		if w.Name != "" {
+			sb.WriteString(" name=" + kdlQuote(w.Name))
+		}
+		if dir != "" {
+			sb.WriteString(" cwd=" + kdlQuote(dir))
+		}
+		if i == 0 {
+			sb.WriteString(" focus=true")
+		}
+		sb.WriteString(" {\n")
+
+		panes := w.Panes
+		if len(panes) == 0 {
+			panes = []PaneSpec{{}}
+		}
+		writeZellijPanes(&sb, w.Layout, panes, 2)
+
+		sb.WriteString("    }\n")
+	}
+
+	sb.WriteString("}\n")
+	return sb.String()
+}
+
+// writeZellijPanes emits the pane tree for one tab. tmux's even-horizontal
+// places panes side by side, which zellij calls a "vertical" split; the main-*
+// layouts become a main pane plus a nested container for the rest. Without a
+// layout, per-pane orientations are honoured as a chain of nested splits.
+func writeZellijPanes(sb *strings.Builder, layout string, panes []PaneSpec, depth int) {
+	indent := strings.Repeat("    ", depth)
+
+	switch layout {
+	case "main-vertical", "main-horizontal":
+		outer, inner := "vertical", "horizontal"
+		if layout == "main-horizontal" {
+			outer, inner = "horizontal", "vertical"
+		}
+		if len(panes) == 1 {
+			writeZellijPane(sb, panes[0], "", depth)
+			return
+		}
+		sb.WriteString(indent + "pane split_direction=" + kdlQuote(outer) + " {\n")
+		writeZellijPane(sb, panes[0], outer, depth+1)
+		sb.WriteString(indent + "    pane split_direction=" + kdlQuote(inner) + " {\n")
+		for _, p := range panes[1:] {
+			writeZellijPane(sb, p, inner, depth+2)
+		}
+		sb.WriteString(indent + "    }\n")
+		sb.WriteString(indent + "}\n")
+	case "":
+		if hasOrientation(panes) {
+			writeZellijChain(sb, panes, "", depth)
+			return
+		}
+		fallthrough
+	default:
+		direction := "vertical"
+		if layout == "even-vertical" {
+			direction = "horizontal"
+		}
+		sb.WriteString(indent + "pane split_direction=" + kdlQuote(direction) + " {\n")
+		for _, p := range panes {
+			writeZellijPane(sb, p, direction, depth+1)
+		}
+		sb.WriteString(indent + "}\n")
+	}
+}
+
+// writeZellijChain nests each pane's split inside the previous one's, so pane
+// N+1 is split off pane N the way tmux's split-window of the active pane does.
+// parent is the split direction of the container the chain is in.
+func writeZellijChain(sb *strings.Builder, panes []PaneSpec, parent string, depth int) {
+	if len(panes) == 1 {
+		writeZellijPane(sb, panes[0], parent, depth)
+		return
+	}
+
+	indent := strings.Repeat("    ", depth)
+	// tmux splits below by default; zellij names splits by the divider line.
+	direction := "horizontal"
+	if panes[1].Orientation == config.OrientationHorizontal {
+		direction = "vertical"
+	}
+	sb.WriteString(indent + "pane split_direction=" + kdlQuote(direction) + " {\n")
+	writeZellijPane(sb, panes[0], direction, depth+1)
+	writeZellijChain(sb, panes[1:], direction, depth+1)
+	sb.WriteString(indent + "}\n")
+}
+
+func hasOrientation(panes []PaneSpec) bool {
+	for _, p := range panes[1:] {
+		if p.Orientation != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// writeZellijPane emits one pane of a container split in direction ("" for a
+// pane on its own).
+func writeZellijPane(sb *strings.Builder, p PaneSpec, direction string, depth int) {
+	indent := strings.Repeat("    ", depth)
+
+	sb.WriteString(indent + "pane")
+	// zellij has one size, along its container's split: side by side panes
+	// ("vertical") take their width, stacked ones their height.
+	size := ""
+	switch direction {
+	case "vertical":
+		size = p.Width
+	case "horizontal":
+		size = p.Height
+	}
+	if strings.HasSuffix(size, "%") {
+		sb.WriteString(" size=" + kdlQuote(size))
+	} else if size != "" {
+		sb.WriteString(" size=" + size)
+	}
+	if p.Path != "" {
+		sb.WriteString(" cwd=" + kdlQuote(p.Path))
+	}
+	if p.Command == "" {
+		sb.WriteString("\n")
+		return
+	}
+
+	shell := os.Getenv("SHELL")
+	if shell == "" {
+		shell = "sh"
+	}
+	sb.WriteString(" command=" + kdlQuote(shell) + " {\n")
+	sb.WriteString(indent + "    args \"-c\" " + kdlQuote(p.Command+"; exec "+shell) + "\n")
+	sb.WriteString(indent + "}\n")
+}
+
+// kdlQuote renders s as a KDL string literal.
+func kdlQuote(s string) string {
+	r := strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`, "\t", `\t`)
+	return `"` + r.Replace(s) + `"`
+}
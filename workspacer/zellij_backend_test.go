@@ -0,0 +1,140 @@
+package workspacer
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/JamesTiberiusKirk/workspacer/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// zellijTabBars is the default_tab_template every layout starts with.
+const zellijTabBars = `    default_tab_template {
+        pane size=1 borderless=true {
+            plugin location="zellij:tab-bar"
+        }
+        children
+        pane size=2 borderless=true {
+            plugin location="zellij:status-bar"
+        }
+    }
+`
+
+func TestZellijLayout(t *testing.T) {
+	t.Setenv("SHELL", "/bin/zsh")
+
+	tests := []struct {
+		name string
+		spec SessionSpec
+		want string
+	}{
+		{
+			name: "Single_bare_pane",
+			spec: SessionSpec{Name: "wk-api", Path: "/src/api", Windows: []WindowSpec{{Name: "api"}}},
+			want: `layout {
+    cwd "/src/api"
+` + zellijTabBars + `    tab name="api" cwd="/src/api" focus=true {
+        pane split_direction="vertical" {
+            pane
+        }
+    }
+}
+`,
+		},
+		{
+			name: "Main_vertical_sizes_by_axis",
+			spec: SessionSpec{Name: "wk-api", Path: "/src/api", Windows: []WindowSpec{
+				{Name: "editor", Layout: "main-vertical", Panes: []PaneSpec{
+					{Command: "nvim", Width: "60%", Height: "90%"},
+					{Width: "50%", Height: "30%"},
+					{Path: "/src/api/web", Height: "12"},
+				}},
+				{Name: "logs", Path: "/var/log", Layout: "even-vertical", Panes: []PaneSpec{
+					{Width: "20%"},
+					{Command: `tail -f "app.log"`, Height: "70%"},
+				}},
+			}},
+			want: `layout {
+    cwd "/src/api"
+` + zellijTabBars + `    tab name="editor" cwd="/src/api" focus=true {
+        pane split_direction="vertical" {
+            pane size="60%" command="/bin/zsh" {
+                args "-c" "nvim; exec /bin/zsh"
+            }
+            pane split_direction="horizontal" {
+                pane size="30%"
+                pane size=12 cwd="/src/api/web"
+            }
+        }
+    }
+    tab name="logs" cwd="/var/log" {
+        pane split_direction="horizontal" {
+            pane
+            pane size="70%" command="/bin/zsh" {
+                args "-c" "tail -f \"app.log\"; exec /bin/zsh"
+            }
+        }
+    }
+}
+`,
+		},
+		{
+			name: "Orientation_chain",
+			spec: SessionSpec{Name: "wk-api", Windows: []WindowSpec{{Panes: []PaneSpec{
+				{Width: "70%"},
+				{Orientation: config.OrientationHorizontal, Width: "40%"},
+				{Orientation: config.OrientationVertical, Height: "25%", Width: "99%"},
+			}}}},
+			want: `layout {
+` + zellijTabBars + `    tab focus=true {
+        pane split_direction="vertical" {
+            pane size="70%"
+            pane split_direction="horizontal" {
+                pane
+                pane size="25%"
+            }
+        }
+    }
+}
+`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := zellijLayout(tt.spec)
+			assert.Equal(t, tt.want, got, "got:\n%s", strings.TrimSpace(got))
+		})
+	}
+}
+
+func TestZellijDescribeSessions(t *testing.T) {
+	dir := t.TempDir()
+	script := `#!/bin/sh
+case "$*" in
+"list-sessions -n")
+	printf 'wk-api [Created 2h 5m ago] (current)\nwk-web [Created 1day ago]\nwk-old [Created 3days ago] (EXITED - attach to resurrect)\n'
+	;;
+"--session wk-api action query-tab-names")
+	printf 'api\nserver\nmy logs\n'
+	;;
+*)
+	exit 1
+	;;
+esac
+`
+	bin := filepath.Join(dir, "zellij")
+	require.NoError(t, os.WriteFile(bin, []byte(script), 0755))
+	t.Setenv("ZELLIJ_BIN", bin)
+
+	info, err := newZellijBackend().DescribeSessions()
+	require.NoError(t, err)
+	attached := true
+	assert.Equal(t, map[string]SessionInfo{
+		"wk-api": {Windows: 3, Attached: &attached},
+		"wk-web": {},
+	}, info)
+}